@@ -1 +1,154 @@
+// Package backtest 提供统一的事件驱动回测框架：
+// 引擎逐根喂入 service.Candle，策略在 OnBar 中返回订单，
+// 引擎负责撮合、持仓与现金记账，并返回完整的回测结果。
 package backtest
+
+import (
+	"fmt"
+	"wolf_street/service"
+)
+
+// Strategy 所有可回测策略需要实现的接口
+type Strategy interface {
+	Name() string
+	// OnBar 每根 K 线收盘后调用一次，返回本根 K 线产生的订单（可为空）
+	OnBar(ctx *Context, bar service.Candle) []Order
+}
+
+// Context 策略在 OnBar 中可见的引擎状态（只读）
+type Context struct {
+	Index    int              // 当前 bar 下标
+	Candles  []service.Candle // 截止到当前 bar 的历史（含当前 bar）
+	Position Position         // 当前持仓
+	Cash     float64          // 当前现金
+	Equity   float64          // 当前权益（按收盘价盯市）
+}
+
+// HigherTimeframe 截止当前 bar 的高周期视图，最后一根为尚未走完的周期，只含已发生的日线
+func (ctx *Context) HigherTimeframe(tf service.Timeframe) []service.Candle {
+	return service.Resample(ctx.Candles, tf)
+}
+
+type Direction int
+
+const (
+	Flat Direction = iota
+	Long
+	Short
+)
+
+func (d Direction) String() string {
+	switch d {
+	case Long:
+		return "LONG"
+	case Short:
+		return "SHORT"
+	default:
+		return "FLAT"
+	}
+}
+
+// TradeMode 允许的交易方向
+type TradeMode int
+
+const (
+	LongOnly  TradeMode = iota // 只做多（默认，Bursa 散户一般无法融券卖空）
+	LongShort                  // 多空双向
+	ShortOnly                  // 只做空
+)
+
+func (m TradeMode) String() string {
+	switch m {
+	case LongOnly:
+		return "long-only"
+	case LongShort:
+		return "long-short"
+	case ShortOnly:
+		return "short-only"
+	default:
+		return "unknown"
+	}
+}
+
+func ParseTradeMode(s string) (TradeMode, error) {
+	for _, m := range []TradeMode{LongOnly, LongShort, ShortOnly} {
+		if m.String() == s {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown trade mode %q", s)
+}
+
+// apply 按交易方向过滤订单：被禁止方向的开仓信号只用于平掉反向持仓
+func (m TradeMode) apply(order Order, pos Position) (Order, bool) {
+	switch {
+	case m == LongOnly && order.Type == OrderEnterShort,
+		m == ShortOnly && order.Type == OrderEnterLong:
+		if pos.IsFlat() {
+			return order, false
+		}
+		order.Type = OrderExit
+	}
+	return order, true
+}
+
+func (d Direction) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+type OrderType int
+
+const (
+	OrderEnterLong OrderType = iota + 1
+	OrderEnterShort
+	OrderExit
+)
+
+func (t OrderType) String() string {
+	switch t {
+	case OrderEnterLong:
+		return "ENTER_LONG"
+	case OrderEnterShort:
+		return "ENTER_SHORT"
+	case OrderExit:
+		return "EXIT"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Order 策略发出的交易意图
+type Order struct {
+	Type    OrderType
+	Qty     float64  // 0 表示由引擎的 Sizer 决定数量；开仓数量都会向下取整到整手
+	Reason  string   // 触发原因，写入成交记录
+	Signals []string // 触发时的信号明细（如 ScoringEngine.Score 的 signals）
+}
+
+// Position 当前持仓
+type Position struct {
+	Direction  Direction
+	Qty        float64
+	EntryPrice float64
+	EntryIndex int
+	EntryDate  string
+	Costs      Costs // 累计费用：开仓费用 + 空头融券费
+	Signals    []string
+
+	HighSinceEntry float64 // 开仓以来最高价
+	LowSinceEntry  float64 // 开仓以来最低价
+}
+
+func (p Position) IsFlat() bool { return p.Direction == Flat || p.Qty == 0 }
+
+// MarketValue 按给定价格计算持仓市值（空头为负）
+func (p Position) MarketValue(price float64) float64 {
+	switch p.Direction {
+	case Long:
+		return p.Qty * price
+	case Short:
+		return -p.Qty * price
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,108 @@
+package backtest
+
+import "math"
+
+// Costs 单笔成交的费用明细
+type Costs struct {
+	Brokerage float64 `json:"brokerage"`  // 佣金
+	StampDuty float64 `json:"stamp_duty"` // 印花税
+	Clearing  float64 `json:"clearing"`   // 清算费
+	Tax       float64 `json:"tax"`        // 佣金/清算费上的服务税（SST）
+	Borrow    float64 `json:"borrow"`     // 空头融券费（仅持仓累计，不属于单笔成交）
+}
+
+func (c Costs) Total() float64 {
+	return c.Brokerage + c.StampDuty + c.Clearing + c.Tax + c.Borrow
+}
+
+func (c Costs) add(o Costs) Costs {
+	return Costs{
+		Brokerage: c.Brokerage + o.Brokerage,
+		StampDuty: c.StampDuty + o.StampDuty,
+		Clearing:  c.Clearing + o.Clearing,
+		Tax:       c.Tax + o.Tax,
+		Borrow:    c.Borrow + o.Borrow,
+	}
+}
+
+// CostModel 交易成本模型，side 为 BUY / SELL
+type CostModel interface {
+	Name() string
+	Cost(side string, price, qty float64) Costs
+}
+
+// ZeroCost 无交易成本
+type ZeroCost struct{}
+
+func (ZeroCost) Name() string                        { return "zero" }
+func (ZeroCost) Cost(string, float64, float64) Costs { return Costs{} }
+
+// BpsCost 通用成本模型：按成交额 bps 收取 + 每笔固定费用，可设最低收费
+type BpsCost struct {
+	Bps   float64 // 万分比，如 10 = 0.1%
+	Fixed float64 // 每笔固定费用
+	Min   float64 // 每笔最低费用
+}
+
+func (m BpsCost) Name() string { return "bps" }
+
+func (m BpsCost) Cost(_ string, price, qty float64) Costs {
+	fee := price*qty*m.Bps/10000 + m.Fixed
+	return Costs{Brokerage: math.Max(fee, m.Min)}
+}
+
+// BursaCost 马来西亚交易所（Bursa Malaysia）费用：
+//   - 佣金：成交额百分比，设最低收费
+//   - 印花税：每 RM1,000（不足按 RM1,000 计）收取固定金额，设上限
+//   - 清算费：成交额百分比，设上限
+//   - 服务税：按佣金和清算费计征
+type BursaCost struct {
+	BrokerageRate float64
+	BrokerageMin  float64
+	StampPer1000  float64
+	StampCap      float64
+	ClearingRate  float64
+	ClearingCap   float64
+	ServiceTax    float64
+}
+
+// DefaultBursaCost 常见网上券商费率
+func DefaultBursaCost() BursaCost {
+	return BursaCost{
+		BrokerageRate: 0.001, // 0.1%
+		BrokerageMin:  8,     // RM8
+		StampPer1000:  1,     // RM1 / RM1,000
+		StampCap:      1000,  // RM1,000
+		ClearingRate:  0.0003,
+		ClearingCap:   1000,
+		ServiceTax:    0.08,
+	}
+}
+
+func (m BursaCost) Name() string { return "bursa" }
+
+func (m BursaCost) Cost(_ string, price, qty float64) Costs {
+	value := price * qty
+	if value <= 0 {
+		return Costs{}
+	}
+
+	brokerage := math.Max(value*m.BrokerageRate, m.BrokerageMin)
+	stamp := capAt(math.Ceil(value/1000)*m.StampPer1000, m.StampCap)
+	clearing := capAt(value*m.ClearingRate, m.ClearingCap)
+
+	return Costs{
+		Brokerage: brokerage,
+		StampDuty: stamp,
+		Clearing:  clearing,
+		Tax:       (brokerage + clearing) * m.ServiceTax,
+	}
+}
+
+// capAt cap <= 0 表示不设上限
+func capAt(v, cap float64) float64 {
+	if cap > 0 && v > cap {
+		return cap
+	}
+	return v
+}
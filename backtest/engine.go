@@ -0,0 +1,329 @@
+package backtest
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"math"
+	"wolf_street/pkginit"
+	"wolf_street/service"
+)
+
+// TradingDaysPerYear 年化使用的交易日数
+const TradingDaysPerYear = 252
+
+type Config struct {
+	InitialCash float64        // 初始资金
+	Mode        TradeMode      // 交易方向，默认只做多
+	BorrowRate  float64        // 空头融券年化费率，按日收取
+	Execution   ExecutionModel // 成交价格模型，默认下一根开盘
+	Costs       CostModel      // 交易成本模型，nil 表示无成本
+	Sizer       Sizer          // 仓位管理，订单未指定数量时使用
+	LotSize     float64        // 每手股数，数量向下取整到整手
+	ExitRules   []ExitRule     // 止损/止盈/移动止损/持有期等离场规则
+	RiskFree    float64        // 年化无风险利率，用于 Sharpe / Sortino
+	CloseAtEnd  bool           // 数据结束时是否按最后收盘价强制平仓
+}
+
+func DefaultConfig() Config {
+	return Config{
+		InitialCash: 100000,
+		Mode:        LongOnly,
+		Execution:   ExecNextBarOpen,
+		Costs:       DefaultBursaCost(),
+		Sizer:       FixedFraction{Fraction: 1},
+		LotSize:     BursaBoardLot,
+		CloseAtEnd:  true,
+		RiskFree:    0.03,
+	}
+}
+
+// Engine 事件驱动回测引擎，同一个 Engine 可以重复 Run 多个策略
+type Engine struct {
+	cfg Config
+}
+
+func NewEngine(cfg Config) *Engine {
+	return &Engine{cfg: cfg}
+}
+
+// account 单次 Run 的账户状态
+type account struct {
+	cash   float64
+	pos    Position
+	costs  CostModel
+	result *Result
+}
+
+func (e *Engine) Run(strategy Strategy, candles []service.Candle) (*Result, error) {
+	if strategy == nil {
+		return nil, errors.New("strategy is nil")
+	}
+	if len(candles) == 0 {
+		return nil, errors.New("candles 数据为空")
+	}
+
+	costs := e.cfg.Costs
+	if costs == nil {
+		costs = ZeroCost{}
+	}
+
+	sizer := e.cfg.Sizer
+	if sizer == nil {
+		sizer = FixedQty{Qty: e.cfg.LotSize}
+	}
+
+	acct := &account{
+		cash:  e.cfg.InitialCash,
+		costs: costs,
+		result: &Result{
+			Strategy:    strategy.Name(),
+			InitialCash: e.cfg.InitialCash,
+			Mode:        e.cfg.Mode.String(),
+			Execution:   e.cfg.Execution.String(),
+			CostModel:   costs.Name(),
+			Sizer:       sizer.Name(),
+		},
+	}
+
+	var pending []Order
+	for i, bar := range candles {
+		// 上一根 bar 发出的订单在本根成交
+		for _, order := range pending {
+			e.execute(acct, sizer, i-1, i, bar, order)
+		}
+		pending = nil
+
+		// 盘中离场规则：同根收盘成交模式下，开仓当根不检查
+		live := !acct.pos.IsFlat() && (acct.pos.EntryIndex < i || e.cfg.Execution.deferred())
+		if live && len(e.cfg.ExitRules) > 0 {
+			check := ExitCheck{Index: i, Bar: bar, Position: acct.pos}
+			if price, reason, hit := checkExits(e.cfg.ExitRules, check); hit {
+				acct.close(i, bar.Date, price, reason, nil)
+			}
+		}
+
+		ctx := &Context{
+			Index:    i,
+			Candles:  candles[:i+1],
+			Position: acct.pos,
+			Cash:     acct.cash,
+			Equity:   acct.equity(bar.Close),
+		}
+
+		orders := strategy.OnBar(ctx, bar)
+		if e.cfg.Execution.deferred() {
+			pending = orders
+		} else {
+			for _, order := range orders {
+				e.execute(acct, sizer, i, i, bar, order)
+			}
+		}
+
+		acct.track(i, bar, e.cfg.Execution.deferred())
+		acct.borrow(bar, e.cfg.BorrowRate)
+		acct.mark(i, bar)
+	}
+
+	if len(pending) > 0 {
+		pkginit.Logger.Debug("Drop orders issued on last bar", zap.Int("count", len(pending)))
+	}
+
+	if e.cfg.CloseAtEnd && !acct.pos.IsFlat() {
+		last := len(candles) - 1
+		acct.close(last, candles[last].Date, candles[last].Close, "数据结束强制平仓", nil)
+		acct.result.Equity[last] = acct.snapshot(last, candles[last])
+	}
+
+	acct.result.FinalEquity = acct.equity(candles[len(candles)-1].Close)
+	acct.result.Metrics = ComputeMetrics(acct.result, e.cfg.RiskFree)
+	return acct.result, nil
+}
+
+// execute 按成交价格模型在 bar 上撮合订单，signalIndex 为产生订单的 bar
+func (e *Engine) execute(acct *account, sizer Sizer, signalIndex, index int, bar service.Candle, order Order) {
+	price := e.cfg.Execution.fillPrice(bar)
+
+	order, ok := e.cfg.Mode.apply(order, acct.pos)
+	if !ok {
+		return
+	}
+
+	switch order.Type {
+	case OrderEnterLong, OrderEnterShort:
+		if !acct.pos.IsFlat() {
+			pkginit.Logger.Debug("Ignore entry order while holding position",
+				zap.String("order", order.Type.String()),
+				zap.String("position", acct.pos.Direction.String()),
+				zap.String("date", bar.Date),
+			)
+			return
+		}
+		dir := Long
+		if order.Type == OrderEnterShort {
+			dir = Short
+		}
+
+		qty := order.Qty
+		if qty <= 0 {
+			qty = sizer.Size(SizingInput{
+				SignalIndex: signalIndex,
+				Price:       price,
+				Cash:        acct.cash,
+				Equity:      acct.equity(price),
+				Direction:   dir,
+				Trades:      acct.result.Trades,
+			})
+		}
+		qty = acct.affordable(price, roundLot(qty, e.cfg.LotSize), e.cfg.LotSize, dir)
+		if qty <= 0 {
+			pkginit.Logger.Debug("Skip entry order: size below one lot or insufficient cash",
+				zap.String("order", order.Type.String()),
+				zap.String("date", bar.Date),
+				zap.Float64("cash", acct.cash),
+			)
+			return
+		}
+		acct.open(index, bar.Date, price, qty, dir, order)
+	case OrderExit:
+		if acct.pos.IsFlat() {
+			return
+		}
+		acct.close(index, bar.Date, price, order.Reason, order.Signals)
+	}
+}
+
+func (a *account) open(index int, date string, price, qty float64, dir Direction, order Order) {
+	side := "BUY"
+	if dir == Short {
+		side = "SELL"
+	}
+	costs := a.costs.Cost(side, price, qty)
+
+	if dir == Long {
+		a.cash -= price * qty
+	} else {
+		a.cash += price * qty
+	}
+	a.cash -= costs.Total()
+
+	a.pos = Position{
+		Direction:  dir,
+		Qty:        qty,
+		EntryPrice: price,
+		EntryIndex: index,
+		EntryDate:  date,
+		Costs:      costs,
+		Signals:    order.Signals,
+
+		HighSinceEntry: price,
+		LowSinceEntry:  price,
+	}
+	a.result.Fills = append(a.result.Fills, Fill{
+		Index: index, Date: date, Side: side, Price: price, Qty: qty, Costs: costs, Reason: order.Reason,
+	})
+}
+
+func (a *account) close(index int, date string, price float64, reason string, signals []string) {
+	pos := a.pos
+
+	side := "SELL"
+	gross := (price - pos.EntryPrice) * pos.Qty
+	if pos.Direction == Short {
+		side = "BUY"
+		gross = -gross
+	}
+	costs := a.costs.Cost(side, price, pos.Qty)
+
+	if pos.Direction == Long {
+		a.cash += price * pos.Qty
+	} else {
+		a.cash -= price * pos.Qty
+	}
+	a.cash -= costs.Total()
+
+	// 净盈亏 = 毛盈亏 - 开仓费用 - 平仓费用
+	tradeCosts := pos.Costs.add(costs)
+	pnl := gross - tradeCosts.Total()
+	ret := 0.0
+	if pos.EntryPrice != 0 {
+		ret = pnl / (pos.EntryPrice * pos.Qty)
+	}
+
+	a.result.Fills = append(a.result.Fills, Fill{
+		Index: index, Date: date, Side: side, Price: price, Qty: pos.Qty, Costs: costs, PnL: pnl, Reason: reason,
+	})
+	a.result.Trades = append(a.result.Trades, Trade{
+		Direction:   pos.Direction,
+		EntryIndex:  pos.EntryIndex,
+		EntryDate:   pos.EntryDate,
+		EntryPrice:  pos.EntryPrice,
+		ExitIndex:   index,
+		ExitDate:    date,
+		ExitPrice:   price,
+		Qty:         pos.Qty,
+		GrossPnL:    gross,
+		Costs:       tradeCosts,
+		PnL:         pnl,
+		ReturnPct:   ret,
+		Signals:     pos.Signals,
+		ExitSignals: signals,
+		ExitReason:  reason,
+	})
+	a.pos = Position{}
+}
+
+// affordable 逐手减少数量，直到成交额加费用不超过可用现金；数量或价格非有限值（NaN/Inf）时返回 0
+func (a *account) affordable(price, qty, lot float64, dir Direction) float64 {
+	if !(price > 0) || math.IsInf(price, 0) || math.IsNaN(qty) || math.IsInf(qty, 0) {
+		return 0
+	}
+	if lot <= 0 {
+		lot = 1
+	}
+
+	side := "BUY"
+	if dir == Short {
+		side = "SELL"
+	}
+
+	// 空头同样以现金作为保证金，按 100% 成交额占用
+	if maxQty := roundLot(a.cash/price, lot); qty > maxQty {
+		qty = maxQty
+	}
+	for qty > 0 && price*qty+a.costs.Cost(side, price, qty).Total() > a.cash {
+		qty -= lot
+	}
+	return math.Max(qty, 0)
+}
+
+// track 更新持仓以来的最高/最低价，供移动止损使用
+func (a *account) track(index int, bar service.Candle, deferred bool) {
+	if a.pos.IsFlat() || (a.pos.EntryIndex == index && !deferred) {
+		return
+	}
+	a.pos.HighSinceEntry = math.Max(a.pos.HighSinceEntry, bar.High)
+	a.pos.LowSinceEntry = math.Min(a.pos.LowSinceEntry, bar.Low)
+}
+
+// borrow 空头持仓按收盘市值收取当日融券费
+func (a *account) borrow(bar service.Candle, rate float64) {
+	if rate <= 0 || a.pos.Direction != Short {
+		return
+	}
+	fee := a.pos.Qty * bar.Close * rate / TradingDaysPerYear
+	a.cash -= fee
+	a.pos.Costs.Borrow += fee
+	a.result.BorrowFees += fee
+}
+
+func (a *account) equity(price float64) float64 {
+	return a.cash + a.pos.MarketValue(price)
+}
+
+func (a *account) snapshot(index int, bar service.Candle) EquityPoint {
+	return EquityPoint{Index: index, Date: bar.Date, Cash: a.cash, Equity: a.equity(bar.Close), Position: a.pos.Direction}
+}
+
+func (a *account) mark(index int, bar service.Candle) {
+	a.result.Equity = append(a.result.Equity, a.snapshot(index, bar))
+}
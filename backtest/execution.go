@@ -0,0 +1,60 @@
+package backtest
+
+import (
+	"fmt"
+	"wolf_street/service"
+)
+
+// ExecutionModel 订单成交价格模型
+//
+// 信号由 bar i 的收盘数据计算得出，若仍按 bar i 收盘价成交属于未来函数（look-ahead），
+// 因此默认在下一根 bar 开盘成交。
+type ExecutionModel int
+
+const (
+	ExecNextBarOpen  ExecutionModel = iota // 下一根开盘价成交（默认）
+	ExecSameBarClose                       // 当根收盘价成交（旧行为，有未来函数）
+	ExecNextBarVWAP                        // 下一根 OHLC 均价近似 VWAP 成交
+)
+
+func (m ExecutionModel) String() string {
+	switch m {
+	case ExecNextBarOpen:
+		return "next-bar-open"
+	case ExecSameBarClose:
+		return "same-bar-close"
+	case ExecNextBarVWAP:
+		return "next-bar-vwap"
+	default:
+		return "unknown"
+	}
+}
+
+func ParseExecutionModel(s string) (ExecutionModel, error) {
+	for _, m := range []ExecutionModel{ExecNextBarOpen, ExecSameBarClose, ExecNextBarVWAP} {
+		if m.String() == s {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown execution model %q", s)
+}
+
+// deferred 是否需要等到下一根 bar 才成交
+func (m ExecutionModel) deferred() bool {
+	return m != ExecSameBarClose
+}
+
+// fillPrice 在成交 bar 上的成交价
+func (m ExecutionModel) fillPrice(bar service.Candle) float64 {
+	switch m {
+	case ExecSameBarClose:
+		return bar.Close
+	case ExecNextBarVWAP:
+		return (bar.Open + bar.High + bar.Low + bar.Close) / 4
+	default:
+		if bar.Open <= 0 {
+			return bar.Close // 缺开盘价时退回收盘价
+		}
+		return bar.Open
+	}
+}
@@ -0,0 +1,212 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"wolf_street/service"
+)
+
+// ExitCheck 检查离场规则时可见的信息
+type ExitCheck struct {
+	Index    int
+	Bar      service.Candle
+	Position Position
+}
+
+// ExitRule 离场规则，可组合使用。
+// 价格类规则用 Bar.High / Bar.Low 判断盘中是否触发，
+// 若开盘已跳空越过触发价则按开盘价成交。
+type ExitRule interface {
+	Name() string
+	Check(in ExitCheck) (price float64, hit bool)
+}
+
+// PercentStop 固定百分比止损
+type PercentStop struct {
+	Pct float64 // 如 0.05 = 5%
+}
+
+func (r PercentStop) Name() string { return fmt.Sprintf("止损%.1f%%", r.Pct*100) }
+
+func (r PercentStop) Check(in ExitCheck) (float64, bool) {
+	p := in.Position
+	if p.Direction == Long {
+		return stopHit(p, in.Bar, p.EntryPrice*(1-r.Pct))
+	}
+	return stopHit(p, in.Bar, p.EntryPrice*(1+r.Pct))
+}
+
+// ATRStop 开仓时 ATR 倍数止损，止损位在开仓后固定
+type ATRStop struct {
+	ATR      []float64 // 如 ScoringEngine.Data.ATR(14)
+	Multiple float64
+}
+
+func NewATRStop(atr []float64, multiple float64) ATRStop {
+	return ATRStop{ATR: atr, Multiple: multiple}
+}
+
+func (r ATRStop) Name() string { return fmt.Sprintf("ATR止损(%.1fx)", r.Multiple) }
+
+func (r ATRStop) Check(in ExitCheck) (float64, bool) {
+	p := in.Position
+	// 使用开仓前一根的 ATR，开仓 bar 的 ATR 在成交时尚未确定
+	atr, ok := valueAt(r.ATR, p.EntryIndex-1)
+	if !ok || atr <= 0 {
+		return 0, false
+	}
+	dist := atr * r.Multiple
+	if p.Direction == Long {
+		return stopHit(p, in.Bar, p.EntryPrice-dist)
+	}
+	return stopHit(p, in.Bar, p.EntryPrice+dist)
+}
+
+// TakeProfit 固定百分比止盈
+type TakeProfit struct {
+	Pct float64
+}
+
+func (r TakeProfit) Name() string { return fmt.Sprintf("止盈%.1f%%", r.Pct*100) }
+
+func (r TakeProfit) Check(in ExitCheck) (float64, bool) {
+	p := in.Position
+	if p.Direction == Long {
+		return targetHit(p, in.Bar, p.EntryPrice*(1+r.Pct))
+	}
+	return targetHit(p, in.Bar, p.EntryPrice*(1-r.Pct))
+}
+
+// TrailingStop 移动止损：距开仓以来最高价（空头为最低价）Pct 或 ATR × Multiple
+//   - 设置 ATR 时按 ATR 距离跟踪（吊灯止损），否则按百分比
+type TrailingStop struct {
+	Pct      float64
+	ATR      []float64
+	Multiple float64
+}
+
+func NewATRTrailingStop(atr []float64, multiple float64) TrailingStop {
+	return TrailingStop{ATR: atr, Multiple: multiple}
+}
+
+func (r TrailingStop) Name() string {
+	if r.ATR != nil {
+		return fmt.Sprintf("ATR移动止损(%.1fx)", r.Multiple)
+	}
+	return fmt.Sprintf("移动止损%.1f%%", r.Pct*100)
+}
+
+func (r TrailingStop) Check(in ExitCheck) (float64, bool) {
+	p := in.Position
+	if r.ATR != nil {
+		atr, ok := valueAt(r.ATR, in.Index-1)
+		if !ok || atr <= 0 {
+			return 0, false
+		}
+		if p.Direction == Long {
+			return stopHit(p, in.Bar, p.HighSinceEntry-atr*r.Multiple)
+		}
+		return stopHit(p, in.Bar, p.LowSinceEntry+atr*r.Multiple)
+	}
+
+	if p.Direction == Long {
+		return stopHit(p, in.Bar, p.HighSinceEntry*(1-r.Pct))
+	}
+	return stopHit(p, in.Bar, p.LowSinceEntry*(1+r.Pct))
+}
+
+// SARStop 抛物线 SAR 止损，使用上一根 bar 的 SAR 值
+type SARStop struct {
+	SAR []float64 // 如 ScoringEngine.Data.SAR(0.02, 0.2)
+}
+
+func (r SARStop) Name() string { return "SAR止损" }
+
+func (r SARStop) Check(in ExitCheck) (float64, bool) {
+	sar, ok := valueAt(r.SAR, in.Index-1)
+	if !ok || sar <= 0 {
+		return 0, false
+	}
+	return stopHit(in.Position, in.Bar, sar)
+}
+
+// MaxHold 最长持有 Bars 根，到期按收盘价离场
+type MaxHold struct {
+	Bars int
+}
+
+func (r MaxHold) Name() string { return fmt.Sprintf("持有%d天到期", r.Bars) }
+
+func (r MaxHold) Check(in ExitCheck) (float64, bool) {
+	if r.Bars <= 0 || in.Index-in.Position.EntryIndex < r.Bars {
+		return 0, false
+	}
+	return in.Bar.Close, true
+}
+
+// stopHit 多头：最低价跌破止损位；空头：最高价升破止损位
+func stopHit(p Position, bar service.Candle, level float64) (float64, bool) {
+	if p.Direction == Long {
+		if bar.Open <= level {
+			return bar.Open, true
+		}
+		if bar.Low <= level {
+			return level, true
+		}
+		return 0, false
+	}
+	if bar.Open >= level {
+		return bar.Open, true
+	}
+	if bar.High >= level {
+		return level, true
+	}
+	return 0, false
+}
+
+// targetHit 多头：最高价升破目标价；空头：最低价跌破目标价
+func targetHit(p Position, bar service.Candle, level float64) (float64, bool) {
+	if p.Direction == Long {
+		if bar.Open >= level {
+			return bar.Open, true
+		}
+		if bar.High >= level {
+			return level, true
+		}
+		return 0, false
+	}
+	if bar.Open <= level {
+		return bar.Open, true
+	}
+	if bar.Low <= level {
+		return level, true
+	}
+	return 0, false
+}
+
+// valueAt 越界或预热期（NaN）时返回 false
+func valueAt(series []float64, i int) (float64, bool) {
+	if i < 0 || i >= len(series) {
+		return 0, false
+	}
+	if math.IsNaN(series[i]) {
+		return 0, false
+	}
+	return series[i], true
+}
+
+// checkExits 依次检查所有规则；同一根 bar 多条规则同时触发时，
+// 无法得知盘中先后顺序，保守地取对持仓最不利的成交价
+func checkExits(rules []ExitRule, in ExitCheck) (price float64, reason string, hit bool) {
+	for _, rule := range rules {
+		p, ok := rule.Check(in)
+		if !ok {
+			continue
+		}
+		worse := in.Position.Direction == Long && p < price || in.Position.Direction == Short && p > price
+		if !hit || worse {
+			price, reason, hit = p, rule.Name(), true
+		}
+	}
+	return
+}
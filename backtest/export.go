@@ -0,0 +1,107 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ExportResult 将成交明细、交易列表、权益曲线导出到 dir：
+//   - trades.csv  每笔开平仓，含费用、净盈亏与触发信号
+//   - fills.csv   每笔成交
+//   - equity.csv  每日权益曲线
+//   - result.json 完整结果（含绩效指标）
+func ExportResult(dir string, r *Result) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := ExportTradesCSV(filepath.Join(dir, "trades.csv"), r.Trades); err != nil {
+		return err
+	}
+	if err := ExportFillsCSV(filepath.Join(dir, "fills.csv"), r.Fills); err != nil {
+		return err
+	}
+	if err := ExportEquityCSV(filepath.Join(dir, "equity.csv"), r.Equity); err != nil {
+		return err
+	}
+	return ExportJSON(filepath.Join(dir, "result.json"), r)
+}
+
+func ExportTradesCSV(path string, trades []Trade) error {
+	header := []string{
+		"direction", "entry_date", "entry_price", "exit_date", "exit_price", "qty",
+		"gross_pnl", "brokerage", "stamp_duty", "clearing", "tax", "borrow", "costs",
+		"pnl", "return_pct", "exit_reason", "signals", "exit_signals",
+	}
+	rows := make([][]string, 0, len(trades))
+	for _, t := range trades {
+		rows = append(rows, []string{
+			t.Direction.String(), t.EntryDate, ftoa(t.EntryPrice), t.ExitDate, ftoa(t.ExitPrice), ftoa(t.Qty),
+			ftoa(t.GrossPnL), ftoa(t.Costs.Brokerage), ftoa(t.Costs.StampDuty), ftoa(t.Costs.Clearing),
+			ftoa(t.Costs.Tax), ftoa(t.Costs.Borrow), ftoa(t.Costs.Total()),
+			ftoa(t.PnL), ftoa(t.ReturnPct), t.ExitReason, strings.Join(t.Signals, "|"), strings.Join(t.ExitSignals, "|"),
+		})
+	}
+	return writeCSV(path, header, rows)
+}
+
+func ExportFillsCSV(path string, fills []Fill) error {
+	header := []string{"index", "date", "side", "price", "qty", "costs", "pnl", "reason"}
+	rows := make([][]string, 0, len(fills))
+	for _, f := range fills {
+		rows = append(rows, []string{
+			strconv.Itoa(f.Index), f.Date, f.Side, ftoa(f.Price), ftoa(f.Qty), ftoa(f.Costs.Total()), ftoa(f.PnL), f.Reason,
+		})
+	}
+	return writeCSV(path, header, rows)
+}
+
+func ExportEquityCSV(path string, equity []EquityPoint) error {
+	header := []string{"index", "date", "cash", "equity", "position"}
+	rows := make([][]string, 0, len(equity))
+	for _, p := range equity {
+		rows = append(rows, []string{
+			strconv.Itoa(p.Index), p.Date, ftoa(p.Cash), ftoa(p.Equity), p.Position.String(),
+		})
+	}
+	return writeCSV(path, header, rows)
+}
+
+func ExportJSON(path string, r *Result) error {
+	out := *r
+	// JSON 不支持 Inf（无亏损交易时的盈亏因子）
+	if math.IsInf(out.Metrics.ProfitFactor, 0) || math.IsNaN(out.Metrics.ProfitFactor) {
+		out.Metrics.ProfitFactor = 0
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func writeCSV(path string, header []string, rows [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func ftoa(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
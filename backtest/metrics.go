@@ -0,0 +1,242 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+)
+
+// Metrics 基于每日权益曲线和已平仓交易计算的绩效指标
+type Metrics struct {
+	StartEquity float64 `json:"start_equity"`
+	EndEquity   float64 `json:"end_equity"`
+	TotalReturn float64 `json:"total_return"`
+	CAGR        float64 `json:"cagr"`
+	AnnualVol   float64 `json:"annual_vol"`
+	Sharpe      float64 `json:"sharpe"`
+	Sortino     float64 `json:"sortino"`
+	Calmar      float64 `json:"calmar"`
+
+	MaxDrawdown         float64 `json:"max_drawdown"`          // 最大回撤（正数，0.2 = 20%）
+	MaxDrawdownDuration int     `json:"max_drawdown_duration"` // 最长回撤持续 bar 数（从高点到收复）
+
+	TotalTrades         int     `json:"total_trades"`
+	Wins                int     `json:"wins"`
+	Losses              int     `json:"losses"`
+	WinRate             float64 `json:"win_rate"`
+	ProfitFactor        float64 `json:"profit_factor"`
+	Expectancy          float64 `json:"expectancy"` // 每笔平均净盈亏
+	AvgWin              float64 `json:"avg_win"`
+	AvgLoss             float64 `json:"avg_loss"` // 负数
+	LongestLosingStreak int     `json:"longest_losing_streak"`
+	Exposure            float64 `json:"exposure"` // 持仓 bar 占比
+}
+
+// ComputeMetrics riskFree 为年化无风险利率
+func ComputeMetrics(r *Result, riskFree float64) Metrics {
+	m := Metrics{StartEquity: r.InitialCash, EndEquity: r.FinalEquity}
+	if len(r.Equity) == 0 {
+		return m
+	}
+
+	equity := make([]float64, len(r.Equity))
+	inMarket := 0
+	for i, p := range r.Equity {
+		equity[i] = p.Equity
+		if p.Position != Flat {
+			inMarket++
+		}
+	}
+	m.Exposure = float64(inMarket) / float64(len(equity))
+
+	if m.StartEquity > 0 {
+		m.TotalReturn = m.EndEquity/m.StartEquity - 1
+		years := float64(len(equity)) / TradingDaysPerYear
+		if years > 0 && m.EndEquity > 0 {
+			m.CAGR = math.Pow(m.EndEquity/m.StartEquity, 1/years) - 1
+		}
+	}
+
+	// 日收益率，第一天相对初始资金
+	returns := make([]float64, 0, len(equity))
+	prev := m.StartEquity
+	for _, e := range equity {
+		if prev > 0 {
+			returns = append(returns, e/prev-1)
+		}
+		prev = e
+	}
+
+	dailyRF := riskFree / TradingDaysPerYear
+	mean, std := meanStd(returns)
+	m.AnnualVol = std * math.Sqrt(TradingDaysPerYear)
+	if std > 0 {
+		m.Sharpe = (mean - dailyRF) / std * math.Sqrt(TradingDaysPerYear)
+	}
+	if dd := downsideDev(returns, dailyRF); dd > 0 {
+		m.Sortino = (mean - dailyRF) / dd * math.Sqrt(TradingDaysPerYear)
+	}
+
+	m.MaxDrawdown, m.MaxDrawdownDuration = maxDrawdown(m.StartEquity, equity)
+	if m.MaxDrawdown > 0 {
+		m.Calmar = m.CAGR / m.MaxDrawdown
+	}
+
+	tradeStats(&m, r.Trades)
+	return m
+}
+
+func tradeStats(m *Metrics, trades []Trade) {
+	m.TotalTrades = len(trades)
+	if m.TotalTrades == 0 {
+		return
+	}
+
+	var grossWin, grossLoss, total float64
+	streak := 0
+	for _, t := range trades {
+		total += t.PnL
+		switch {
+		case t.PnL > 0:
+			m.Wins++
+			grossWin += t.PnL
+			streak = 0
+		case t.PnL < 0:
+			m.Losses++
+			grossLoss -= t.PnL
+			streak++
+			if streak > m.LongestLosingStreak {
+				m.LongestLosingStreak = streak
+			}
+		default:
+			streak = 0
+		}
+	}
+
+	m.WinRate = float64(m.Wins) / float64(m.TotalTrades)
+	m.Expectancy = total / float64(m.TotalTrades)
+	if m.Wins > 0 {
+		m.AvgWin = grossWin / float64(m.Wins)
+	}
+	if m.Losses > 0 {
+		m.AvgLoss = -grossLoss / float64(m.Losses)
+	}
+	switch {
+	case grossLoss > 0:
+		m.ProfitFactor = grossWin / grossLoss
+	case grossWin > 0:
+		m.ProfitFactor = math.Inf(1)
+	}
+}
+
+// maxDrawdown 返回最大回撤比例与最长回撤持续 bar 数
+func maxDrawdown(start float64, equity []float64) (float64, int) {
+	peak := start
+	peakIndex := -1
+	maxDD, maxDur := 0.0, 0
+	for i, e := range equity {
+		if e >= peak {
+			peak = e
+			peakIndex = i
+			continue
+		}
+		if peak > 0 {
+			maxDD = math.Max(maxDD, (peak-e)/peak)
+		}
+		if dur := i - peakIndex; dur > maxDur {
+			maxDur = dur
+		}
+	}
+	return maxDD, maxDur
+}
+
+func meanStd(xs []float64) (float64, float64) {
+	if len(xs) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, x := range xs {
+		sum += x
+	}
+	mean := sum / float64(len(xs))
+	if len(xs) < 2 {
+		return mean, 0
+	}
+	variance := 0.0
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(xs)-1))
+}
+
+// downsideDev 低于目标收益部分的半标准差
+func downsideDev(xs []float64, target float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, x := range xs {
+		if d := x - target; d < 0 {
+			sum += d * d
+		}
+	}
+	return math.Sqrt(sum / float64(len(xs)))
+}
+
+// MetricRow 绩效指标的展示行
+type MetricRow struct {
+	Name  string
+	Value string
+}
+
+// Rows 按展示顺序格式化全部指标，供终端表格与 HTML 报告共用
+func (m Metrics) Rows() []MetricRow {
+	return []MetricRow{
+		{"初始权益", fmt.Sprintf("%.2f", m.StartEquity)},
+		{"期末权益", fmt.Sprintf("%.2f", m.EndEquity)},
+		{"总收益率", pct(m.TotalReturn)},
+		{"年化收益 CAGR", pct(m.CAGR)},
+		{"年化波动率", pct(m.AnnualVol)},
+		{"Sharpe", fmt.Sprintf("%.2f", m.Sharpe)},
+		{"Sortino", fmt.Sprintf("%.2f", m.Sortino)},
+		{"Calmar", fmt.Sprintf("%.2f", m.Calmar)},
+		{"最大回撤", pct(m.MaxDrawdown)},
+		{"最长回撤(bar)", fmt.Sprintf("%d", m.MaxDrawdownDuration)},
+		{"交易次数", fmt.Sprintf("%d", m.TotalTrades)},
+		{"胜率", pct(m.WinRate)},
+		{"盈亏因子", fmt.Sprintf("%.2f", m.ProfitFactor)},
+		{"期望值/笔", fmt.Sprintf("%.2f", m.Expectancy)},
+		{"平均盈利", fmt.Sprintf("%.2f", m.AvgWin)},
+		{"平均亏损", fmt.Sprintf("%.2f", m.AvgLoss)},
+		{"最长连亏", fmt.Sprintf("%d", m.LongestLosingStreak)},
+		{"持仓时间占比", pct(m.Exposure)},
+	}
+}
+
+func PrintMetrics(m Metrics) {
+	fmt.Printf("\n ===== Performance Metrics ===== \n\n")
+	fmt.Println("+----------------------+-----------------+")
+	for _, row := range m.Rows() {
+		fmt.Printf("| %s | %15s |\n", padRight(row.Name, 20), row.Value)
+	}
+	fmt.Println("+----------------------+-----------------+")
+}
+
+func pct(v float64) string {
+	return fmt.Sprintf("%.2f%%", v*100)
+}
+
+// padRight 按显示宽度补齐（中文字符占两列）
+func padRight(s string, width int) string {
+	w := 0
+	for _, r := range s {
+		if r > 0x2E80 {
+			w += 2
+		} else {
+			w++
+		}
+	}
+	for ; w < width; w++ {
+		s += " "
+	}
+	return s
+}
@@ -0,0 +1,95 @@
+package backtest
+
+import "fmt"
+
+// Fill 单笔成交记录
+type Fill struct {
+	Index  int     `json:"index"`
+	Date   string  `json:"date"`
+	Side   string  `json:"side"` // BUY / SELL
+	Price  float64 `json:"price"`
+	Qty    float64 `json:"qty"`
+	Costs  Costs   `json:"costs"`
+	PnL    float64 `json:"pnl"` // 平仓成交的已实现净盈亏（含开平仓费用），开仓为 0
+	Reason string  `json:"reason"`
+}
+
+// Trade 一次完整的开平仓（round trip）
+type Trade struct {
+	Direction   Direction `json:"direction"`
+	EntryIndex  int       `json:"entry_index"`
+	EntryDate   string    `json:"entry_date"`
+	EntryPrice  float64   `json:"entry_price"`
+	ExitIndex   int       `json:"exit_index"`
+	ExitDate    string    `json:"exit_date"`
+	ExitPrice   float64   `json:"exit_price"`
+	Qty         float64   `json:"qty"`
+	GrossPnL    float64   `json:"gross_pnl"` // 未扣费用
+	Costs       Costs     `json:"costs"`     // 开仓 + 平仓费用
+	PnL         float64   `json:"pnl"`       // 扣除费用后的净盈亏
+	ReturnPct   float64   `json:"return_pct"`
+	Signals     []string  `json:"signals"`      // 开仓时的信号
+	ExitSignals []string  `json:"exit_signals"` // 平仓订单的信号（规则离场时为空）
+	ExitReason  string    `json:"exit_reason"`
+}
+
+// EquityPoint 每根 K 线收盘后的账户快照
+type EquityPoint struct {
+	Index    int       `json:"index"`
+	Date     string    `json:"date"`
+	Cash     float64   `json:"cash"`
+	Equity   float64   `json:"equity"`
+	Position Direction `json:"position"`
+}
+
+// Result 一次回测的完整结果
+type Result struct {
+	Strategy    string        `json:"strategy"`
+	Mode        string        `json:"mode"`
+	Execution   string        `json:"execution"`
+	CostModel   string        `json:"cost_model"`
+	Sizer       string        `json:"sizer"`
+	InitialCash float64       `json:"initial_cash"`
+	FinalEquity float64       `json:"final_equity"`
+	BorrowFees  float64       `json:"borrow_fees"` // 空头融券费合计
+	Fills       []Fill        `json:"fills"`
+	Trades      []Trade       `json:"trades"`
+	Equity      []EquityPoint `json:"equity"`
+	Metrics     Metrics       `json:"metrics"`
+}
+
+// TotalCosts 所有成交的费用与融券费合计
+func (r *Result) TotalCosts() float64 {
+	total := r.BorrowFees
+	for _, f := range r.Fills {
+		total += f.Costs.Total()
+	}
+	return total
+}
+
+// TotalPnL 所有已平仓交易的净盈亏合计
+func (r *Result) TotalPnL() float64 {
+	total := 0.0
+	for _, t := range r.Trades {
+		total += t.PnL
+	}
+	return total
+}
+
+func PrintResult(r *Result) {
+	fmt.Printf("\n ===== Backtest Result: %s ===== \n\n", r.Strategy)
+
+	for _, t := range r.Trades {
+		fmt.Printf("%-5s | %s @ %.4f -> %s @ %.4f | Qty: %.0f | Gross: %.2f | Costs: %.2f | PnL: %.2f (%.2f%%) | %s\n",
+			t.Direction, t.EntryDate, t.EntryPrice, t.ExitDate, t.ExitPrice, t.Qty, t.GrossPnL, t.Costs.Total(), t.PnL, t.ReturnPct*100, t.ExitReason)
+	}
+
+	fmt.Printf("\n交易方向: %s\n", r.Mode)
+	fmt.Printf("成交模型: %s\n", r.Execution)
+	fmt.Printf("成本模型: %s\n", r.CostModel)
+	fmt.Printf("仓位管理: %s\n", r.Sizer)
+	fmt.Printf("总费用: %.2f\n", r.TotalCosts())
+	fmt.Printf("总盈亏(净): %.2f\n", r.TotalPnL())
+
+	PrintMetrics(r.Metrics)
+}
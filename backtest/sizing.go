@@ -0,0 +1,160 @@
+package backtest
+
+import "math"
+
+// BursaBoardLot Bursa Malaysia 每手 100 股
+const BursaBoardLot = 100
+
+// SizingInput 计算开仓数量所需的账户与行情信息
+type SizingInput struct {
+	SignalIndex int     // 产生信号的 bar（用于读取指标，避免未来函数）
+	Price       float64 // 预计成交价
+	Cash        float64
+	Equity      float64
+	Direction   Direction
+	Trades      []Trade // 截止目前已平仓的交易
+}
+
+// Sizer 仓位管理策略，返回未取整的股数，由引擎按每手股数向下取整
+type Sizer interface {
+	Name() string
+	Size(in SizingInput) float64
+}
+
+// FixedQty 每次固定股数
+type FixedQty struct {
+	Qty float64
+}
+
+func (s FixedQty) Name() string             { return "fixed-qty" }
+func (s FixedQty) Size(SizingInput) float64 { return s.Qty }
+
+// FixedCapital 每次投入固定金额
+type FixedCapital struct {
+	Amount float64
+}
+
+func (s FixedCapital) Name() string { return "fixed-capital" }
+
+func (s FixedCapital) Size(in SizingInput) float64 {
+	if in.Price <= 0 {
+		return 0
+	}
+	return s.Amount / in.Price
+}
+
+// FixedFraction 每次投入当前权益的固定比例
+type FixedFraction struct {
+	Fraction float64 // 0~1
+}
+
+func (s FixedFraction) Name() string { return "fixed-fraction" }
+
+func (s FixedFraction) Size(in SizingInput) float64 {
+	if in.Price <= 0 {
+		return 0
+	}
+	return in.Equity * s.Fraction / in.Price
+}
+
+// ATRTarget 波动率目标仓位：每笔风险 = 权益 × RiskFraction，止损距离 = ATR × Multiple
+type ATRTarget struct {
+	ATR          []float64 // service.CalculateATR 的结果
+	RiskFraction float64   // 每笔愿意承受的权益亏损比例，如 0.01
+	Multiple     float64   // 止损距离的 ATR 倍数，如 2
+}
+
+func NewATRTarget(atr []float64, riskFraction, multiple float64) ATRTarget {
+	return ATRTarget{ATR: atr, RiskFraction: riskFraction, Multiple: multiple}
+}
+
+func (s ATRTarget) Name() string { return "atr-target" }
+
+func (s ATRTarget) Size(in SizingInput) float64 {
+	// ATR 预热期为 NaN，!(atr > 0) 同时排除 NaN 与非正值
+	atr, ok := valueAt(s.ATR, in.SignalIndex)
+	if !ok || !(atr > 0) || s.Multiple <= 0 {
+		return 0
+	}
+	return in.Equity * s.RiskFraction / (atr * s.Multiple)
+}
+
+// Kelly 凯利公式仓位：f* = W - (1-W)/R
+//   - WinRate/Payoff 为 0 时按已平仓交易估算
+//   - 已平仓交易不足 MinTrades 时使用 Fallback 比例
+//   - Scale 为分数凯利（如 0.5 半凯利），MaxFraction 为上限
+type Kelly struct {
+	WinRate     float64
+	Payoff      float64 // 平均盈利 / 平均亏损
+	Scale       float64
+	MaxFraction float64
+	MinTrades   int
+	Fallback    float64
+}
+
+func DefaultKelly() Kelly {
+	return Kelly{Scale: 0.5, MaxFraction: 1, MinTrades: 10, Fallback: 0.1}
+}
+
+func (s Kelly) Name() string { return "kelly" }
+
+func (s Kelly) Size(in SizingInput) float64 {
+	if in.Price <= 0 {
+		return 0
+	}
+	return in.Equity * s.Fraction(in.Trades) / in.Price
+}
+
+// Fraction 根据参数或历史交易计算投入比例
+func (s Kelly) Fraction(trades []Trade) float64 {
+	w, r := s.WinRate, s.Payoff
+	if w <= 0 || r <= 0 {
+		if len(trades) < s.MinTrades {
+			return s.Fallback
+		}
+		w, r = winPayoff(trades)
+		if r <= 0 {
+			return s.Fallback
+		}
+	}
+
+	f := (w - (1-w)/r) * s.Scale
+	if s.MaxFraction > 0 {
+		f = math.Min(f, s.MaxFraction)
+	}
+	return math.Max(f, 0)
+}
+
+// winPayoff 胜率与盈亏比，没有亏损交易时盈亏比按无穷大处理
+func winPayoff(trades []Trade) (winRate, payoff float64) {
+	var wins, losses int
+	var winSum, lossSum float64
+	for _, t := range trades {
+		if t.PnL > 0 {
+			wins++
+			winSum += t.PnL
+		} else if t.PnL < 0 {
+			losses++
+			lossSum -= t.PnL
+		}
+	}
+	if len(trades) == 0 {
+		return 0, 0
+	}
+	winRate = float64(wins) / float64(len(trades))
+	if wins == 0 {
+		return winRate, 0
+	}
+	if losses == 0 {
+		return winRate, math.Inf(1)
+	}
+	return winRate, (winSum / float64(wins)) / (lossSum / float64(losses))
+}
+
+// roundLot 向下取整到整手
+func roundLot(qty, lot float64) float64 {
+	if lot <= 0 {
+		return math.Floor(qty)
+	}
+	return math.Floor(qty/lot) * lot
+}
@@ -0,0 +1,74 @@
+package backtest
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"math"
+	"testing"
+	"wolf_street/pkginit"
+	"wolf_street/service"
+)
+
+func init() {
+	pkginit.Logger = zap.NewNop()
+}
+
+// enterEveryBar 空仓时每根 K 线都发出开多信号，数量交给 Sizer
+type enterEveryBar struct{}
+
+func (enterEveryBar) Name() string { return "enter-every-bar" }
+
+func (enterEveryBar) OnBar(ctx *Context, _ service.Candle) []Order {
+	if !ctx.Position.IsFlat() {
+		return nil
+	}
+	return []Order{{Type: OrderEnterLong, Reason: "test"}}
+}
+
+// TestATRTargetSkipsWarmup ATR 预热期（NaN）不开仓，预热结束后正常按风险开仓，权益始终为有限值
+func TestATRTargetSkipsWarmup(t *testing.T) {
+	candles := make([]service.Candle, 60)
+	for i := range candles {
+		p := 10 + math.Sin(float64(i)/5)
+		candles[i] = service.Candle{Date: fmt.Sprintf("bar-%02d", i), Open: p, High: p + 0.2, Low: p - 0.2, Close: p, Volume: 1000}
+	}
+	highs, lows, closes := make([]float64, len(candles)), make([]float64, len(candles)), make([]float64, len(candles))
+	for i, c := range candles {
+		highs[i], lows[i], closes[i] = c.High, c.Low, c.Close
+	}
+	atr := service.CalculateATR(highs, lows, closes, 14)
+
+	cfg := DefaultConfig()
+	cfg.Sizer = NewATRTarget(atr, 0.01, 2)
+	result, err := NewEngine(cfg).Run(enterEveryBar{}, candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Trades) == 0 {
+		t.Fatal("no trades after ATR warm-up")
+	}
+	for _, tr := range result.Trades {
+		if math.IsNaN(tr.Qty) || tr.Qty <= 0 {
+			t.Fatalf("trade qty %v", tr.Qty)
+		}
+		if tr.EntryIndex <= 14 {
+			t.Fatalf("entered at bar %d during ATR warm-up", tr.EntryIndex)
+		}
+	}
+	if math.IsNaN(result.FinalEquity) || math.IsInf(result.FinalEquity, 0) {
+		t.Fatalf("final equity %v", result.FinalEquity)
+	}
+}
+
+func TestAffordableRejectsNonFinite(t *testing.T) {
+	a := &account{cash: 100000, costs: ZeroCost{}}
+	for _, qty := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if got := a.affordable(10, qty, 100, Long); got != 0 {
+			t.Fatalf("affordable(qty=%v) = %v, want 0", qty, got)
+		}
+	}
+	if got := a.affordable(math.NaN(), 100, 100, Long); got != 0 {
+		t.Fatalf("affordable(price=NaN) = %v, want 0", got)
+	}
+}
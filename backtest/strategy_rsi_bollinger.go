@@ -0,0 +1,74 @@
+package backtest
+
+import (
+	"fmt"
+	"wolf_street/service"
+)
+
+// RSIBollingerStrategy 均值回归：RSI 超卖且收盘跌破布林下轨买入，
+// RSI 回到 50 以上或持有满 HoldDays 天卖出（只做多）
+type RSIBollingerStrategy struct {
+	RSI       []float64
+	Bollinger service.BollingerBand
+	Warmup    int
+	HoldDays  int
+}
+
+func NewRSIBollingerStrategy(candles []service.Candle) *RSIBollingerStrategy {
+	prices := make([]float64, len(candles))
+	for i, c := range candles {
+		prices[i] = c.Close
+	}
+
+	return &RSIBollingerStrategy{
+		RSI:       service.CalculateRSI(prices, 14),
+		Bollinger: service.CalculateBollinger(prices, 20),
+		Warmup:    20,
+		HoldDays:  3,
+	}
+}
+
+func (s *RSIBollingerStrategy) Name() string {
+	return "RSI + Bollinger Band"
+}
+
+func (s *RSIBollingerStrategy) OnBar(ctx *Context, bar service.Candle) []Order {
+	i := ctx.Index
+	if i < s.Warmup {
+		return nil
+	}
+
+	if ctx.Position.IsFlat() {
+		if s.RSI[i] < 30 && bar.Close < s.Bollinger.LowerBand[i] {
+			return []Order{{
+				Type:    OrderEnterLong,
+				Reason:  fmt.Sprintf("RSI=%.2f 跌破布林下轨", s.RSI[i]),
+				Signals: []string{"RSI超卖", "布林带下轨突破"},
+			}}
+		}
+		return nil
+	}
+
+	holdDays := i - ctx.Position.EntryIndex
+	if s.RSI[i] > 50 {
+		return []Order{{Type: OrderExit, Reason: fmt.Sprintf("RSI=%.2f 回到50以上", s.RSI[i])}}
+	}
+	if holdDays >= s.HoldDays {
+		return []Order{{Type: OrderExit, Reason: fmt.Sprintf("持有%d天到期", holdDays)}}
+	}
+	return nil
+}
+
+func StrategyRSIBollinger(candles []service.Candle) (*Result, error) {
+	return RunRSIBollinger(candles, DefaultConfig())
+}
+
+func RunRSIBollinger(candles []service.Candle, cfg Config) (*Result, error) {
+	result, err := NewEngine(cfg).Run(NewRSIBollingerStrategy(candles), candles)
+	if err != nil {
+		return nil, err
+	}
+	PrintResult(result)
+
+	return result, nil
+}
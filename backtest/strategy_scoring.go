@@ -0,0 +1,107 @@
+package backtest
+
+import (
+	"fmt"
+	"strings"
+	"wolf_street/service"
+)
+
+// ScoringStrategy 基于 ScoringEngine 总分的多空策略：
+// score ≥ threshold 开多 / 平空，score ≤ -threshold 开空 / 平多
+type ScoringStrategy struct {
+	Engine    *service.ScoringEngine
+	Threshold int
+}
+
+func NewScoringStrategy(se *service.ScoringEngine, threshold int) *ScoringStrategy {
+	return &ScoringStrategy{Engine: se, Threshold: threshold}
+}
+
+func (s *ScoringStrategy) Name() string {
+	return fmt.Sprintf("ScoringEngine(threshold=%d)", s.Threshold)
+}
+
+func (s *ScoringStrategy) OnBar(ctx *Context, bar service.Candle) []Order {
+	score, signals := s.Engine.Score(ctx.Index)
+	reason := fmt.Sprintf("score=%d", score)
+
+	switch ctx.Position.Direction {
+	case Flat:
+		if score >= s.Threshold {
+			return []Order{{Type: OrderEnterLong, Reason: reason, Signals: signals}}
+		}
+		if score <= -s.Threshold {
+			return []Order{{Type: OrderEnterShort, Reason: reason, Signals: signals}}
+		}
+	case Long:
+		if score <= -s.Threshold {
+			return []Order{{Type: OrderExit, Reason: reason, Signals: signals}}
+		}
+	case Short:
+		if score >= s.Threshold {
+			return []Order{{Type: OrderExit, Reason: reason, Signals: signals}}
+		}
+	}
+	return nil
+}
+
+// ScoringExitRules 评分策略默认的离场规则：2 倍 ATR 止损 + 3 倍 ATR 移动止损
+func ScoringExitRules(se *service.ScoringEngine) []ExitRule {
+	return []ExitRule{
+		NewATRStop(se.Data.ATR(14), 2),
+		NewATRTrailingStop(se.Data.ATR(14), 3),
+	}
+}
+
+func StrategyScoringEngine(candles []service.Candle) (*Result, error) {
+	return StrategyScoringEngineWithConfig(candles, service.DefaultScoringConfig())
+}
+
+// StrategyScoringEngineWithConfig 按评分配置组装 ScoringEngine 并回测
+func StrategyScoringEngineWithConfig(candles []service.Candle, scoring service.ScoringConfig) (*Result, error) {
+	se, err := service.NewScoringEngineWithConfig(candles, scoring)
+	if err != nil {
+		return nil, err
+	}
+	cfg := DefaultConfig()
+	cfg.ExitRules = ScoringExitRules(se)
+	return RunScoringEngine(se, 2, cfg)
+}
+
+// RunScoringEngine 用已计算好的 ScoringEngine 回测，便于调用方复用指标（如生成报告）
+func RunScoringEngine(se *service.ScoringEngine, threshold int, cfg Config) (*Result, error) {
+	candles := se.Candles
+
+	fmt.Println(" \n\n ======= Scoring Engine Result: ======= \n ")
+	// 只打印前 10 根，其余 bar 由回测引擎按需评分
+	for i := 0; i < min(10, len(se.Prices)); i++ {
+		score, signals := se.Score(i)
+		tradeSignal := service.GenerateTradeSignal(score)
+		fmt.Printf(" (%d.) %s = $ %f | Score: %d, tradeSignal: %s , Signals: %v%s\n", i+1, candles[i].Date, candles[i].Close, score, tradeSignal, signals, formatActiveBars(se.SignalStatus(i)))
+	}
+
+	/* Trading */
+	result, err := NewEngine(cfg).Run(NewScoringStrategy(se, threshold), candles)
+	if err != nil {
+		return nil, err
+	}
+	PrintResult(result)
+
+	return result, nil
+}
+
+// formatActiveBars 各信号已持续的 bar 数，冷却中未计分的信号一并列出；未设置 Tracker 时为空
+func formatActiveBars(statuses []service.SignalStatus) string {
+	if len(statuses) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(statuses))
+	for _, st := range statuses {
+		part := fmt.Sprintf("%s×%d", st.Name, st.ActiveBars)
+		if !st.Fired {
+			part += "(冷却)"
+		}
+		parts = append(parts, part)
+	}
+	return " | Active: " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,53 @@
+package backtest
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"wolf_street/pkginit"
+	"wolf_street/service"
+)
+
+// BacktestTrades 兼容旧接口：用回测引擎跑 ScoringStrategy，并按成交明细返回
+func BacktestTrades(se *service.ScoringEngine, threshold int) []service.Trade {
+	result, err := NewEngine(DefaultConfig()).Run(NewScoringStrategy(se, threshold), se.Candles)
+	if err != nil {
+		pkginit.Logger.Error("BacktestTrades failed", zap.Error(err))
+		return nil
+	}
+
+	trades := make([]service.Trade, 0, len(result.Fills))
+	for _, f := range result.Fills {
+		trades = append(trades, service.Trade{Date: f.Date, Signal: f.Side, Price: f.Price, Qty: f.Qty, Cost: f.Costs.Total(), PnL: f.PnL})
+	}
+	return trades
+}
+
+func PrintTradeStats(trades []service.Trade) {
+	totalPnL := 0.0
+	totalCost := 0.0
+	winCount := 0
+	lossCount := 0
+
+	fmt.Printf("\n ===== PrintTradeStats ===== \n\n")
+
+	for _, trade := range trades {
+		fmt.Printf("%s | %s %.0f @ %.2f | Cost: %.2f | PnL: %.2f\n", trade.Date, trade.Signal, trade.Qty, trade.Price, trade.Cost, trade.PnL)
+		totalPnL += trade.PnL
+		totalCost += trade.Cost
+		if trade.PnL > 0 {
+			winCount++
+		} else if trade.PnL < 0 {
+			lossCount++
+		}
+	}
+
+	totalTrades := winCount + lossCount
+	fmt.Printf("\n总费用: %.2f\n", totalCost)
+	fmt.Printf("总盈亏(净): %.2f\n", totalPnL)
+	if totalTrades > 0 {
+		fmt.Printf("胜率: %.2f%%\n", float64(winCount)/float64(totalTrades)*100)
+	} else {
+		fmt.Println("胜率: N/A（无已平仓交易）")
+	}
+	fmt.Printf("总交易次数: %d\n", totalTrades)
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"time"
+	"wolf_street/backtest"
+	"wolf_street/model"
+	"wolf_street/pkginit"
+	"wolf_street/report"
+	"wolf_street/service"
+	"wolf_street/util"
+)
+
+// runParams 一次回测运行所需参数（交互菜单与命令行共用）
+type runParams struct {
+	Strategy  model.Strategy
+	Stock     model.Stock
+	Candles   []service.Candle
+	Threshold int
+	Config    backtest.Config
+	OutDir    string // 为空则不导出
+	Report    bool
+	Adaptive  bool   // 振荡指标使用分位数阈值（股票池中 adaptive_thresholds 为 true 时也会启用）
+	Scoring   string // 评分配置文件（YAML），为空使用默认配置
+}
+
+func backtestCommand() *cli.Command {
+	defaults := backtest.DefaultConfig()
+
+	return &cli.Command{
+		Name:  "backtest",
+		Usage: "Run a backtest non-interactively",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "strategy", Usage: "strategy key or ID (see list-strategies)", Required: true},
+			&cli.StringFlag{Name: "stock", Usage: "stock code or number (see list-stocks)", Required: true},
+			&cli.StringFlag{Name: "from", Usage: "start date, YYYY-MM-DD"},
+			&cli.StringFlag{Name: "to", Usage: "end date, YYYY-MM-DD"},
+			&cli.StringFlag{Name: "timeframe", Value: service.Daily.String(), Usage: "bar size: 1d | Nd | 1w | 1mo"},
+			&cli.IntFlag{Name: "threshold", Value: 2, Usage: "score threshold for the scoring strategy"},
+			&cli.BoolFlag{Name: "adaptive", Usage: "use rolling percentile thresholds for oscillators"},
+			&cli.StringFlag{Name: "scoring-config", Usage: "YAML file selecting and parameterizing scoring evaluators (see list-evaluators)"},
+			&cli.Float64Flag{Name: "capital", Value: defaults.InitialCash, Usage: "starting capital"},
+			&cli.StringFlag{Name: "mode", Value: defaults.Mode.String(), Usage: "long-only | long-short | short-only"},
+			&cli.StringFlag{Name: "execution", Value: defaults.Execution.String(), Usage: "next-bar-open | same-bar-close | next-bar-vwap"},
+			&cli.Float64Flag{Name: "borrow-rate", Usage: "annual borrow fee rate for short positions"},
+			&cli.Float64Flag{Name: "stop-pct", Usage: "fixed percent stop loss, e.g. 0.05"},
+			&cli.Float64Flag{Name: "take-profit", Usage: "percent take profit, e.g. 0.1"},
+			&cli.IntFlag{Name: "max-hold", Usage: "maximum holding period in bars"},
+			&cli.BoolFlag{Name: "no-costs", Usage: "disable transaction costs"},
+			&cli.StringFlag{Name: "out", Value: "output", Usage: "output directory, empty to skip export"},
+			&cli.BoolFlag{Name: "no-report", Usage: "skip HTML report"},
+			&cli.BoolFlag{Name: "strict", Usage: "fail when the data file contains bad rows"},
+			&cli.BoolFlag{Name: "no-adjust", Usage: "use raw prices, ignore the corporate actions file"},
+			&cli.BoolFlag{Name: "total-return", Usage: "also back-adjust for cash dividends (dividends reinvested)"},
+		},
+		Action: func(c *cli.Context) error {
+			strategy, err := model.FindStrategy(c.String("strategy"))
+			if err != nil {
+				return err
+			}
+			stock, err := model.FindStock(c.String("stock"))
+			if err != nil {
+				return err
+			}
+
+			tf, err := service.ParseTimeframe(c.String("timeframe"))
+			if err != nil {
+				return err
+			}
+
+			cfg := backtest.DefaultConfig()
+			cfg.InitialCash = c.Float64("capital")
+			cfg.BorrowRate = c.Float64("borrow-rate")
+			if cfg.Mode, err = backtest.ParseTradeMode(c.String("mode")); err != nil {
+				return err
+			}
+			if cfg.Execution, err = backtest.ParseExecutionModel(c.String("execution")); err != nil {
+				return err
+			}
+			if c.Bool("no-costs") {
+				cfg.Costs = backtest.ZeroCost{}
+			}
+			if v := c.Float64("stop-pct"); v > 0 {
+				cfg.ExitRules = append(cfg.ExitRules, backtest.PercentStop{Pct: v})
+			}
+			if v := c.Float64("take-profit"); v > 0 {
+				cfg.ExitRules = append(cfg.ExitRules, backtest.TakeProfit{Pct: v})
+			}
+			if v := c.Int("max-hold"); v > 0 {
+				cfg.ExitRules = append(cfg.ExitRules, backtest.MaxHold{Bars: v})
+			}
+
+			candles, err := loadCandles(stock, loadOptions{
+				Strict:      c.Bool("strict"),
+				Raw:         c.Bool("no-adjust"),
+				TotalReturn: c.Bool("total-return"),
+			})
+			if err != nil {
+				return fmt.Errorf("load candle data for %s: %w", stock.Code, err)
+			}
+			candles, err = util.FilterCandlesByDate(candles, c.String("from"), c.String("to"))
+			if err != nil {
+				return err
+			}
+			if tf != service.Daily {
+				candles = service.Resample(candles, tf)
+			}
+			if err := checkCandles(candles); err != nil {
+				return err
+			}
+
+			outDir := ""
+			if dir := c.String("out"); dir != "" {
+				outDir = filepath.Join(dir, stock.Code, time.Now().Format("20060102-150405"))
+			}
+
+			return runStrategy(runParams{
+				Strategy:  strategy,
+				Stock:     stock,
+				Candles:   candles,
+				Threshold: c.Int("threshold"),
+				Config:    cfg,
+				OutDir:    outDir,
+				Report:    !c.Bool("no-report"),
+				Adaptive:  c.Bool("adaptive"),
+				Scoring:   c.String("scoring-config"),
+			})
+		},
+	}
+}
+
+func listStocksCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "list-stocks",
+		Usage: "List available stocks",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "sector", Usage: "only list stocks in this sector"},
+		},
+		Action: func(c *cli.Context) error {
+			stocks, err := model.GetStocksBySector(c.String("sector"))
+			if err != nil {
+				return err
+			}
+			for _, s := range stocks {
+				fmt.Printf("%-8s %-6s %-5s %-14s %-4s %s\n", s.Code, s.Number, s.Board, s.Sector, s.Currency, s.Name)
+			}
+			return nil
+		},
+	}
+}
+
+func listStrategiesCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "list-strategies",
+		Usage: "List available strategies",
+		Action: func(c *cli.Context) error {
+			strategies, err := model.GetAllStrategy()
+			if err != nil {
+				return err
+			}
+			for _, s := range strategies {
+				fmt.Printf("%-3d %-15s %-25s [%s]\n", s.ID, s.Key, s.Name, s.Category)
+			}
+			return nil
+		},
+	}
+}
+
+func listEvaluatorsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "list-evaluators",
+		Usage: "List registered scoring evaluators",
+		Action: func(c *cli.Context) error {
+			for _, name := range service.RegisteredEvaluators() {
+				fmt.Println(name)
+			}
+			return nil
+		},
+	}
+}
+
+func interactiveAction(c *cli.Context) error {
+	/* Step 1: Strategy Selection */
+	selectedStrategy, err := util.CliMenuSelectStrategy(15)
+	if err != nil {
+		pkginit.Logger.Error("Strategy selection failed", zap.Error(err))
+		return err
+	}
+
+	var stock model.Stock
+	var candles []service.Candle
+
+	// Step 2 & 3: Stock Selection + Load Candle Data Loop
+	for {
+		stock, err = util.CliMenuSelectStock(10, c.String("sector"))
+		if err != nil {
+			pkginit.Logger.Error("Stock selection failed", zap.Error(err))
+			return err
+		}
+
+		candles, err = loadCandles(stock, loadOptions{})
+		if err != nil {
+
+			pkginit.Logger.Error("LoadCandleData", zap.Error(err))
+
+			// 文件不存在：提示用户重新选股，不退出
+			if errors.Is(err, os.ErrNotExist) {
+				fmt.Printf("Data file for %s (%s) not found. Please select another stock.\n\n", stock.Name, stock.Code)
+				continue // 重新回到股票选择
+			}
+
+			// 其他文件异常则退出
+			pkginit.Logger.Error("Failed to load candle data", zap.Error(err))
+			return err
+		}
+
+		if err := checkCandles(candles); err != nil {
+			return err
+		}
+
+		// 成功加载Candle数据，退出循环
+		break
+	}
+
+	// Step 4: Execute Strategy + Export
+	return runStrategy(runParams{
+		Strategy:  selectedStrategy,
+		Stock:     stock,
+		Candles:   candles,
+		Threshold: 2,
+		Config:    backtest.DefaultConfig(),
+		OutDir:    filepath.Join("output", stock.Code, time.Now().Format("20060102-150405")),
+		Report:    true,
+	})
+}
+
+type loadOptions struct {
+	Strict      bool // 数据文件有坏行时报错
+	Raw         bool // 不做公司行动复权
+	TotalReturn bool // 股息也复权
+}
+
+// loadCandles 非 strict 模式下跳过坏行并记录警告，默认按公司行动文件复权
+func loadCandles(stock model.Stock, opt loadOptions) ([]service.Candle, error) {
+	candles, err := util.LoadStockCandles(stock)
+	var perr *util.CandleParseError
+	if !opt.Strict && errors.As(err, &perr) {
+		pkginit.Logger.Warn("Skipped bad candle rows", zap.String("stock", stock.Code), zap.Int("rows", len(perr.Rows)), zap.Error(err))
+		err = nil
+	}
+	if err != nil || opt.Raw {
+		return candles, err
+	}
+	return util.AdjustStockCandles(stock, candles, service.AdjustOptions{TotalReturn: opt.TotalReturn})
+}
+
+func checkCandles(candles []service.Candle) error {
+	if len(candles) == 0 {
+		return fmt.Errorf("candles 数据为空")
+	}
+	if len(candles) < 30 {
+		return fmt.Errorf("candles 数据不足, 至少需要30根K线")
+	}
+	return nil
+}
+
+// percentileWindow 分位数阈值回看窗口，约一年交易日
+const percentileWindow = 250
+
+func runStrategy(p runParams) error {
+	var result *backtest.Result
+	var se *service.ScoringEngine
+	var err error
+
+	switch p.Strategy.ID {
+	case 1:
+		scoring := service.DefaultScoringConfig()
+		if p.Scoring != "" {
+			if scoring, err = service.LoadScoringConfig(p.Scoring); err != nil {
+				return err
+			}
+		}
+		if p.Adaptive || p.Stock.AdaptiveThresholds {
+			scoring.UsePercentileBands(percentileWindow)
+		}
+		if se, err = service.NewScoringEngineWithProgress(p.Candles, scoring, service.NewProgressBarFunc(p.Stock.Code)); err != nil {
+			return err
+		}
+		cfg := p.Config
+		cfg.ExitRules = append(backtest.ScoringExitRules(se), cfg.ExitRules...)
+		result, err = backtest.RunScoringEngine(se, p.Threshold, cfg)
+	case 2:
+		result, err = backtest.RunRSIBollinger(p.Candles, p.Config)
+	default:
+		return fmt.Errorf("strategy %s not implemented yet", p.Strategy.Name)
+	}
+	if err != nil {
+		pkginit.Logger.Error("Strategy failed:", zap.Any("Strategy", p.Strategy.Name), zap.Error(err))
+		return err
+	}
+
+	if p.OutDir == "" {
+		return nil
+	}
+
+	// Export trade log & equity curve
+	if err := backtest.ExportResult(p.OutDir, result); err != nil {
+		pkginit.Logger.Error("Export result failed", zap.String("dir", p.OutDir), zap.Error(err))
+		return err
+	}
+
+	if p.Report {
+		reportPath := filepath.Join(p.OutDir, "report.html")
+		err = report.WriteHTML(reportPath, report.Input{
+			Title:   fmt.Sprintf("%s (%s) - %s", p.Stock.Name, p.Stock.Code, p.Strategy.Name),
+			Candles: p.Candles,
+			Result:  result,
+			Engine:  se,
+		})
+		if err != nil {
+			pkginit.Logger.Error("Write report failed", zap.String("path", reportPath), zap.Error(err))
+			return err
+		}
+	}
+	fmt.Printf("\nResult exported to %s\n", p.OutDir)
+
+	return nil
+}
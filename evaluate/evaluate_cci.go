@@ -0,0 +1,43 @@
+package evaluate
+
+// =======================================================
+// CCI 评分：突破 +100 视为多头强势，跌破 -100 视为空头强势
+// =======================================================
+
+type CCIConfig struct {
+	Bands      Bands           // 仅使用 Low / High（默认 -100 / +100）
+	Percentile PercentileBands // 启用后 Low / High 取近期 CCI 的分位数
+
+	WStrongBull float64 // CCI > High 加分
+	WStrongBear float64 // CCI < Low 扣分
+}
+
+func DefaultCCIConfig() CCIConfig {
+	return CCIConfig{
+		Bands:       Bands{SevereLow: -200, Low: -100, High: 100, SevereHigh: 200},
+		Percentile:  DefaultPercentileBands(),
+		WStrongBull: 1,
+		WStrongBear: -1,
+	}
+}
+
+func EvaluateCCISignals(cci []float64, idx int, cfg CCIConfig) (EvalResult, error) {
+	res := EvalResult{
+		Score:      0,
+		Signals:    []string{},
+		Components: map[string]float64{},
+	}
+	if idx < 0 || idx >= len(cci) {
+		return res, ErrIndexOutOfRange
+	}
+
+	b, _ := cfg.Percentile.Resolve(cci, idx, cfg.Bands)
+	switch {
+	case cci[idx] > b.High:
+		hit(&res, "cci_bull", "CCI强多头", cfg.WStrongBull)
+	case cci[idx] < b.Low:
+		hit(&res, "cci_bear", "CCI强空头", cfg.WStrongBear)
+	}
+	res.Score = sumComponents(res.Components)
+	return res, nil
+}
@@ -0,0 +1,112 @@
+package evaluate
+
+import "math"
+
+// =======================================================
+// 一目均衡表评分：价格与云层、转换线 / 基准线交叉、云层翻转、迟行线确认
+// =======================================================
+
+// IchimokuLines 一目均衡表各线（避免 import service）
+// SenkouA / SenkouB 已向前平移 Displacement 根，长度可比 K 线多出 Displacement 个未来云层
+type IchimokuLines struct {
+	Tenkan, Kijun    []float64
+	SenkouA, SenkouB []float64
+	Displacement     int
+}
+
+type IchimokuConfig struct {
+	WAboveCloud, WBelowCloud float64 // 收盘价在云层上方加分 / 下方扣分，云中不计分
+	WTKGolden, WTKDeath      float64 // 转换线上穿 / 下穿基准线
+	WBullTwist, WBearTwist   float64 // 未来云层翻转：先行带 A 上穿 / 下穿先行带 B
+	WChikouBull, WChikouBear float64 // 迟行线确认：收盘价高于 / 低于 Displacement 根前的收盘价
+}
+
+func DefaultIchimokuConfig() IchimokuConfig {
+	return IchimokuConfig{
+		WAboveCloud: 1, WBelowCloud: -1,
+		WTKGolden: 1, WTKDeath: -1,
+		WBullTwist: 0.5, WBearTwist: -0.5,
+		WChikouBull: 0.5, WChikouBear: -0.5,
+	}
+}
+
+// EvaluateIchimoku 各项独立计分，所需的线仍在预热期（NaN）时该项跳过
+// 转换线 / 基准线都是区间中值，常有数根相等，交叉与翻转按“由持平或反向变为越过”判定
+// 云层翻转看的是 idx+Displacement 处的未来云层，它只由当根及之前的数据算出，不含未来信息
+func EvaluateIchimoku(lines IchimokuLines, closes []float64, idx int, cfg IchimokuConfig) (EvalResult, error) {
+	res := EvalResult{
+		Score:      0,
+		Signals:    []string{},
+		Components: map[string]float64{},
+	}
+	if idx < 0 || idx >= len(closes) || idx >= len(lines.Tenkan) || idx >= len(lines.Kijun) {
+		return res, ErrIndexOutOfRange
+	}
+	price := closes[idx]
+
+	// ---------- 1) 价格与云层 ----------
+	if a, b, ok := cloudAt(lines, idx); ok {
+		switch {
+		case price > math.Max(a, b):
+			hit(&res, "ichimoku_cloud", "价格位于一目均衡表云层上方", cfg.WAboveCloud)
+		case price < math.Min(a, b):
+			hit(&res, "ichimoku_cloud", "价格位于一目均衡表云层下方", cfg.WBelowCloud)
+		}
+	}
+
+	// ---------- 2) 转换线 / 基准线交叉 ----------
+	if idx >= 1 && finite(lines.Tenkan[idx-1], lines.Kijun[idx-1], lines.Tenkan[idx], lines.Kijun[idx]) {
+		prev := lines.Tenkan[idx-1] - lines.Kijun[idx-1]
+		cur := lines.Tenkan[idx] - lines.Kijun[idx]
+		switch {
+		case prev <= 0 && cur > 0:
+			hit(&res, "ichimoku_tk", "一目均衡表转换线上穿基准线", cfg.WTKGolden)
+		case prev >= 0 && cur < 0:
+			hit(&res, "ichimoku_tk", "一目均衡表转换线下穿基准线", cfg.WTKDeath)
+		}
+	}
+
+	// ---------- 3) 云层翻转 ----------
+	future := idx + lines.Displacement
+	prevA, prevB, okPrev := cloudAt(lines, future-1)
+	curA, curB, okCur := cloudAt(lines, future)
+	if okPrev && okCur {
+		switch {
+		case prevA <= prevB && curA > curB:
+			hit(&res, "ichimoku_twist", "一目均衡表云层由空翻多", cfg.WBullTwist)
+		case prevA >= prevB && curA < curB:
+			hit(&res, "ichimoku_twist", "一目均衡表云层由多翻空", cfg.WBearTwist)
+		}
+	}
+
+	// ---------- 4) 迟行线确认 ----------
+	if past := idx - lines.Displacement; past >= 0 && finite(closes[past]) {
+		switch {
+		case price > closes[past]:
+			hit(&res, "ichimoku_chikou", "一目均衡表迟行线确认偏多", cfg.WChikouBull)
+		case price < closes[past]:
+			hit(&res, "ichimoku_chikou", "一目均衡表迟行线确认偏空", cfg.WChikouBear)
+		}
+	}
+
+	res.Score = sumComponents(res.Components)
+	return res, nil
+}
+
+// cloudAt 第 i 根的先行带 A / B，越界或仍为 NaN 时 ok 为 false
+func cloudAt(lines IchimokuLines, i int) (a, b float64, ok bool) {
+	if i < 0 || i >= len(lines.SenkouA) || i >= len(lines.SenkouB) {
+		return 0, 0, false
+	}
+	a, b = lines.SenkouA[i], lines.SenkouB[i]
+	return a, b, finite(a, b)
+}
+
+func finite(vs ...float64) bool {
+	for _, v := range vs {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
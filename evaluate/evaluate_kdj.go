@@ -13,6 +13,7 @@ type KDJConfig struct {
 	JMomentumStep                   float64
 	KDWideGap                       float64
 	PersistenceN                    int
+	Percentile                      PercentileBands // 启用后 J 的四档阈值取近期 J 的分位数
 
 	ScoreOverbought, ScoreExtremeOverbought int
 	ScoreOversold, ScoreExtremeOversold     int
@@ -31,6 +32,7 @@ func DefaultKDJConfig() KDJConfig {
 		JMomentumStep: 10,
 		KDWideGap:     20,
 		PersistenceN:  3,
+		Percentile:    DefaultPercentileBands(),
 
 		ScoreOverbought: -1, ScoreExtremeOverbought: -2,
 		ScoreOversold: +1, ScoreExtremeOversold: +2,
@@ -66,40 +68,65 @@ func EvaluateKDJSignals(kdj KDJSeries, prices PriceSeries, index int) (int, []st
 }
 
 func EvaluateKDJSignalsWithConfig(kdj KDJSeries, prices PriceSeries, index int, cfg KDJConfig) (int, []string, error) {
+	res, err := EvaluateKDJ(kdj, prices, index, cfg)
+	if err != nil {
+		return 0, nil, err
+	}
+	return int(res.Score), res.Signals, nil
+}
+
+// EvaluateKDJ 同 EvaluateKDJSignalsWithConfig，返回带子项与单信号得分的 EvalResult
+func EvaluateKDJ(kdj KDJSeries, prices PriceSeries, index int, cfg KDJConfig) (EvalResult, error) {
+	res := EvalResult{Signals: []string{}, Components: map[string]float64{}}
 	if kdj == nil || kdj.Len() == 0 {
-		return 0, nil, ErrNotEnoughData
+		return res, ErrNotEnoughData
 	}
 	if index < 0 || index >= kdj.Len() {
-		return 0, nil, ErrIndexOutOfRange
+		return res, ErrIndexOutOfRange
 	}
 	if index == 0 {
-		return 0, nil, nil
+		return res, nil
 	}
-	score, signals := kdjScore(kdj, prices, index, cfg)
-	return score, signals, nil
+	kdjScore(&res, kdj, prices, index, cfg)
+	res.Score = sumComponents(res.Components)
+	return res, nil
 }
 
-func kdjScore(s KDJSeries, prices PriceSeries, i int, cfg KDJConfig) (int, []string) {
+func kdjScore(res *EvalResult, s KDJSeries, prices PriceSeries, i int, cfg KDJConfig) {
 	k, d, j := s.K(i), s.D(i), s.J(i)
 	kp, dp, jp := s.K(i-1), s.D(i-1), s.J(i-1)
 
-	score := 0
-	var signals []string
+	// 0) 动态分位阈值：信号名不带具体数值，便于冷却按名称识别同一信号
+	dynamic := false
+	if cfg.Percentile.Enabled {
+		start := max(0, i-cfg.Percentile.Window+1)
+		js := make([]float64, 0, i-start+1)
+		for t := start; t <= i; t++ {
+			js = append(js, s.J(t))
+		}
+		fixed := Bands{SevereLow: cfg.JExtremeSold, Low: cfg.JSold, High: cfg.JOverbought, SevereHigh: cfg.JExtremeOverbought}
+		var b Bands
+		if b, dynamic = cfg.Percentile.Resolve(js, len(js)-1, fixed); dynamic {
+			cfg.JExtremeSold, cfg.JSold, cfg.JOverbought, cfg.JExtremeOverbought = b.SevereLow, b.Low, b.High, b.SevereHigh
+		}
+	}
+	band := func(name, format string, args ...any) string {
+		if dynamic {
+			return name + "(动态分位)"
+		}
+		return name + fmt.Sprintf(format, args...)
+	}
 
 	// 1) J bands
 	switch {
 	case j >= cfg.JExtremeOverbought:
-		score += cfg.ScoreExtremeOverbought
-		signals = append(signals, fmt.Sprintf("KDJ 极度超买(J≥%.0f)", cfg.JExtremeOverbought))
+		hit(res, "j_band", band("KDJ 极度超买", "(J≥%.0f)", cfg.JExtremeOverbought), float64(cfg.ScoreExtremeOverbought))
 	case j >= cfg.JOverbought:
-		score += cfg.ScoreOverbought
-		signals = append(signals, fmt.Sprintf("KDJ 超买(%.0f≤J<%.0f)", cfg.JOverbought, cfg.JExtremeOverbought))
+		hit(res, "j_band", band("KDJ 超买", "(%.0f≤J<%.0f)", cfg.JOverbought, cfg.JExtremeOverbought), float64(cfg.ScoreOverbought))
 	case j <= cfg.JExtremeSold:
-		score += cfg.ScoreExtremeOversold
-		signals = append(signals, fmt.Sprintf("KDJ 极度超卖(J≤%.0f)", cfg.JExtremeSold))
+		hit(res, "j_band", band("KDJ 极度超卖", "(J≤%.0f)", cfg.JExtremeSold), float64(cfg.ScoreExtremeOversold))
 	case j <= cfg.JSold:
-		score += cfg.ScoreOversold
-		signals = append(signals, fmt.Sprintf("KDJ 超卖(%.0f<J≤%.0f)", cfg.JExtremeSold, cfg.JSold))
+		hit(res, "j_band", band("KDJ 超卖", "(%.0f<J≤%.0f)", cfg.JExtremeSold, cfg.JSold), float64(cfg.ScoreOversold))
 	}
 
 	// 2) Crosses
@@ -110,35 +137,29 @@ func kdjScore(s KDJSeries, prices PriceSeries, i int, cfg KDJConfig) (int, []str
 		if k < 50 && d < 50 {
 			w = cfg.ScoreGoldenLow
 		}
-		score += w
-		signals = append(signals, fmt.Sprintf("KDJ 金叉(权重 %+d)", w))
+		hit(res, "cross", fmt.Sprintf("KDJ 金叉(权重 %+d)", w), float64(w))
 	}
 	if death {
 		w := cfg.ScoreDeath
 		if k > 50 && d > 50 {
 			w = cfg.ScoreDeathHigh
 		}
-		score += w
-		signals = append(signals, fmt.Sprintf("KDJ 死叉(权重 %+d)", w))
+		hit(res, "cross", fmt.Sprintf("KDJ 死叉(权重 %+d)", w), float64(w))
 	}
 
 	// 3) Momentum ΔJ
 	if dJ := j - jp; dJ >= cfg.JMomentumStep {
-		score += cfg.ScoreJUp
-		signals = append(signals, fmt.Sprintf("KDJ 动能上行(ΔJ≥%.0f)", cfg.JMomentumStep))
+		hit(res, "momentum", fmt.Sprintf("KDJ 动能上行(ΔJ≥%.0f)", cfg.JMomentumStep), float64(cfg.ScoreJUp))
 	} else if dJ <= -cfg.JMomentumStep {
-		score += cfg.ScoreJDown
-		signals = append(signals, fmt.Sprintf("KDJ 动能下行(ΔJ≤-%.0f)", cfg.JMomentumStep))
+		hit(res, "momentum", fmt.Sprintf("KDJ 动能下行(ΔJ≤-%.0f)", cfg.JMomentumStep), float64(cfg.ScoreJDown))
 	}
 
 	// 4) K-D spread
 	if diffKD := math.Abs(k - d); diffKD >= cfg.KDWideGap {
 		if k > d {
-			score += cfg.ScoreKDom
-			signals = append(signals, fmt.Sprintf("K>D 强势(乖离≥%.0f)", cfg.KDWideGap))
+			hit(res, "kd_gap", fmt.Sprintf("K>D 强势(乖离≥%.0f)", cfg.KDWideGap), float64(cfg.ScoreKDom))
 		} else {
-			score += cfg.ScoreDDom
-			signals = append(signals, fmt.Sprintf("K<D 弱势(乖离≥%.0f)", cfg.KDWideGap))
+			hit(res, "kd_gap", fmt.Sprintf("K<D 弱势(乖离≥%.0f)", cfg.KDWideGap), float64(cfg.ScoreDDom))
 		}
 	}
 
@@ -160,12 +181,10 @@ func kdjScore(s KDJSeries, prices PriceSeries, i int, cfg KDJConfig) (int, []str
 			}
 		}
 		if highCnt == need && need == cfg.PersistenceN {
-			score += cfg.ScoreHighPersist
-			signals = append(signals, fmt.Sprintf("KDJ 高位钝化(%d根)", cfg.PersistenceN))
+			hit(res, "persist", fmt.Sprintf("KDJ 高位钝化(%d根)", cfg.PersistenceN), float64(cfg.ScoreHighPersist))
 		}
 		if lowCnt == need && need == cfg.PersistenceN {
-			score += cfg.ScoreLowPersist
-			signals = append(signals, fmt.Sprintf("KDJ 低位钝化(%d根)", cfg.PersistenceN))
+			hit(res, "persist", fmt.Sprintf("KDJ 低位钝化(%d根)", cfg.PersistenceN), float64(cfg.ScoreLowPersist))
 		}
 	}
 
@@ -178,14 +197,10 @@ func kdjScore(s KDJSeries, prices PriceSeries, i int, cfg KDJConfig) (int, []str
 		jLL := s.J(i) < s.J(i-1) && s.J(i-1) < s.J(i-2)
 
 		if priceHH && !jHH {
-			score += cfg.ScoreBearDiv
-			signals = append(signals, "KDJ 看跌背离(价新高/J未新高)")
+			hit(res, "divergence", "KDJ 看跌背离(价新高/J未新高)", float64(cfg.ScoreBearDiv))
 		}
 		if priceLL && !jLL {
-			score += cfg.ScoreBullDiv
-			signals = append(signals, "KDJ 看涨背离(价新低/J未新低)")
+			hit(res, "divergence", "KDJ 看涨背离(价新低/J未新低)", float64(cfg.ScoreBullDiv))
 		}
 	}
-
-	return score, signals
 }
@@ -4,28 +4,48 @@ package evaluate
 // 附加：RSI 评分（可选）
 // =======================================================
 
-func EvaluateRSISignals(rsiIndex []float64, idx int) (EvalResult, error) {
+type RSIConfig struct {
+	Bands      Bands           // 固定阈值（默认 20 / 30 / 70 / 80）
+	Percentile PercentileBands // 启用后按近期 RSI 分位数动态计算阈值
 
-	rsi := rsiIndex[idx]
+	WSevereOS, WOS float64 // 严重 / 轻度超卖加分
+	WOB, WSevereOB float64 // 轻度 / 严重超买扣分
+}
+
+func DefaultRSIConfig() RSIConfig {
+	return RSIConfig{
+		Bands:      Bands{SevereLow: 20, Low: 30, High: 70, SevereHigh: 80},
+		Percentile: DefaultPercentileBands(),
+		WSevereOS:  2, WOS: 1,
+		WOB: -1, WSevereOB: -2,
+	}
+}
+
+func EvaluateRSISignals(rsiIndex []float64, idx int) (EvalResult, error) {
+	return EvaluateRSISignalsWithConfig(rsiIndex, idx, DefaultRSIConfig())
+}
 
+func EvaluateRSISignalsWithConfig(rsiIndex []float64, idx int, cfg RSIConfig) (EvalResult, error) {
 	res := EvalResult{
 		Score:      0,
 		Signals:    []string{},
 		Components: map[string]float64{},
 	}
+	if idx < 0 || idx >= len(rsiIndex) {
+		return res, ErrIndexOutOfRange
+	}
+
+	rsi := rsiIndex[idx]
+	b, _ := cfg.Percentile.Resolve(rsiIndex, idx, cfg.Bands)
 	switch {
-	case rsi < 20:
-		acc(&res, "rsi_severe_os", 2)
-		res.Signals = append(res.Signals, "RSI严重超卖")
-	case rsi >= 20 && rsi < 30:
-		acc(&res, "rsi_os", 1)
-		res.Signals = append(res.Signals, "RSI轻度超卖")
-	case rsi > 70 && rsi <= 80:
-		acc(&res, "rsi_ob", -1)
-		res.Signals = append(res.Signals, "RSI轻度超买")
-	case rsi > 80:
-		acc(&res, "rsi_severe_ob", -2)
-		res.Signals = append(res.Signals, "RSI严重超买")
+	case rsi < b.SevereLow:
+		hit(&res, "rsi_severe_os", "RSI严重超卖", cfg.WSevereOS)
+	case rsi >= b.SevereLow && rsi < b.Low:
+		hit(&res, "rsi_os", "RSI轻度超卖", cfg.WOS)
+	case rsi > b.High && rsi <= b.SevereHigh:
+		hit(&res, "rsi_ob", "RSI轻度超买", cfg.WOB)
+	case rsi > b.SevereHigh:
+		hit(&res, "rsi_severe_ob", "RSI严重超买", cfg.WSevereOB)
 	}
 	res.Score = sumComponents(res.Components)
 	return res, nil
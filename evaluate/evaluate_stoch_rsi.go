@@ -13,20 +13,21 @@ import (
 // =======================================================
 
 // Engine: 扩展用的引擎数据结构
-//   - 当前 StochRSI 单 series 版不依赖它
-//   - 如果未来要加 K/D 金叉判断、多周期 HTF 共振，可直接用它
+//   - 单 series 版 EvaluateStochRSISignals 不依赖它
+//   - EvaluateStochRSIEngine 在单 series 评分基础上使用 K/D 做金叉/死叉、使用高周期序列做多周期共振
 type Engine struct {
 	StochRSI    []float64 // 标准化到 0~1 的 StochRSI 数值序列
 	StochK      []float64 // StochRSI 对应的 K 值（0~100 或 0~1）
 	StochD      []float64 // StochRSI 对应的 D 值（0~100 或 0~1）
-	StochRSIHTF []float64 // 高周期 StochRSI（如 4 小时），用于多周期共振判断
+	StochRSIHTF []float64 // 高周期 StochRSI（如周线），已对齐到本周期下标，未就绪为 NaN
 }
 
 // EvalResult: 单次评估的返回结果
 type EvalResult struct {
-	Score      float64            // 总得分
-	Signals    []string           // 命中的信号列表（中文描述）
-	Components map[string]float64 // 每个子项得分构成，方便调试 & 调参
+	Score        float64            // 总得分
+	Signals      []string           // 命中的信号列表（中文描述）
+	Components   map[string]float64 // 每个子项得分构成，方便调试 & 调参
+	SignalScores map[string]float64 // 每个信号自身的得分，供调用方做信号冷却 / 去抖
 }
 
 // =======================================================
@@ -50,9 +51,9 @@ type StochRSIConfig struct {
 	SlopeLookback int // 计算斜率的回看周期数
 	MinRiseBars   int // 底部区域要求连续抬升的 bar 数
 
-	// ---- 金叉/死叉（本单 series 版不启用，但保留配置）----
+	// ---- 金叉/死叉（EvaluateStochRSIEngine 使用 StochK / StochD）----
 	EnableCrossBoost    bool    // 是否开启金叉/死叉加分
-	CrossoverHysteresis int     // 金叉/死叉信号滞后期
+	CrossoverHysteresis int     // 金叉/死叉需保持的 bar 数，交叉后连续 N 根同侧才确认（1 = 当根交叉）
 	WCrossUpInOS        float64 // 超卖区金叉加分
 	WCrossDownInOB      float64 // 超买区死叉扣分
 
@@ -62,52 +63,54 @@ type StochRSIConfig struct {
 	WPersistOB   float64 // 超买持续附加分
 	CooldownBars int     // 信号冷却期（调用方外部实现）
 
-	// ---- 多周期共振（本单 series 版不启用）----
+	// ---- 多周期共振（EvaluateStochRSIEngine 使用 StochRSIHTF）----
 	EnableMTF bool
-	WMTFOS    float64
-	WMTFOB    float64
+	WMTFOS    float64 // 本周期与高周期同时超卖加分
+	WMTFOB    float64 // 本周期与高周期同时超买扣分
 
 	// ---- 百分位动态阈值 ----
-	UsePercentile    bool                                      // 是否启用动态分位阈值
-	PercentileFunc   func(series []float64, p float64) float64 // 分位数计算函数
-	PercentileWindow int                                       // 分位数计算窗口
-	POversold        float64                                   // 低分位（默认 0.2）
-	POverbought      float64                                   // 高分位（默认 0.8）
+	UsePercentile        bool                                      // 是否启用动态分位阈值
+	PercentileFunc       func(series []float64, p float64) float64 // 分位数计算函数，nil 时使用 PercentileLinear
+	PercentileWindow     int                                       // 分位数计算窗口
+	PercentileMinHistory int                                       // 窗口内有效样本少于此数时退回固定阈值（同 PercentileBands.MinHistory）
+	POversold            float64                                   // 低分位（默认 0.2）
+	POverbought          float64                                   // 高分位（默认 0.8）
 
 	// ---- 归一化控制 ----
-	NormalizeKD bool // 如果 K/D 是 0~100 则自动归一化到 0~1（series 版未用）
+	NormalizeKD bool // 如果 K/D 是 0~100 则自动归一化到 0~1
 }
 
 // 默认配置生成函数
 func DefaultStochRSIConfig() StochRSIConfig {
 	return StochRSIConfig{
-		SevereOversold:      0.10,
-		Oversold:            0.20,
-		Overbought:          0.80,
-		SevereOverbought:    0.90,
-		WSevereOS:           2.0,
-		WOS:                 1.0,
-		WOB:                 -1.0,
-		WSevereOB:           -2.0,
-		SlopeLookback:       3,
-		MinRiseBars:         2,
-		EnableCrossBoost:    false, // 本版默认关闭
-		CrossoverHysteresis: 1,
-		WCrossUpInOS:        2.0,
-		WCrossDownInOB:      -2.0,
-		PersistBars:         3,
-		WPersistOS:          0.5,
-		WPersistOB:          -0.5,
-		CooldownBars:        2,
-		EnableMTF:           false,
-		WMTFOS:              0.5,
-		WMTFOB:              -0.5,
-		UsePercentile:       false,
-		PercentileFunc:      nil,
-		PercentileWindow:    100,
-		POversold:           0.20,
-		POverbought:         0.80,
-		NormalizeKD:         true,
+		SevereOversold:       0.10,
+		Oversold:             0.20,
+		Overbought:           0.80,
+		SevereOverbought:     0.90,
+		WSevereOS:            2.0,
+		WOS:                  1.0,
+		WOB:                  -1.0,
+		WSevereOB:            -2.0,
+		SlopeLookback:        3,
+		MinRiseBars:          2,
+		EnableCrossBoost:     false, // 需要 K/D，默认关闭
+		CrossoverHysteresis:  1,
+		WCrossUpInOS:         2.0,
+		WCrossDownInOB:       -2.0,
+		PersistBars:          3,
+		WPersistOS:           0.5,
+		WPersistOB:           -0.5,
+		CooldownBars:         2,
+		EnableMTF:            false,
+		WMTFOS:               0.5,
+		WMTFOB:               -0.5,
+		UsePercentile:        false,
+		PercentileFunc:       nil,
+		PercentileWindow:     100,
+		PercentileMinHistory: 60,
+		POversold:            0.20,
+		POverbought:          0.80,
+		NormalizeKD:          true,
 	}
 }
 
@@ -140,57 +143,39 @@ func EvaluateStochRSISignals(series []float64, index int, cfg StochRSIConfig) (E
 	s := series[index]
 
 	// ---------- 百分位动态阈值 ----------
+	cfg = resolveStochRSIBands(series, index, cfg)
 	os, ob := cfg.Oversold, cfg.Overbought
-	if cfg.UsePercentile && cfg.PercentileFunc != nil && cfg.PercentileWindow > 10 {
-		start := max(0, index-cfg.PercentileWindow+1)
-		window := series[start : index+1]
-		os = cfg.PercentileFunc(window, cfg.POversold)
-		ob = cfg.PercentileFunc(window, cfg.POverbought)
-
-		// 动态调整严重超卖/超买
-		cfg.SevereOversold = clamp01(os * 0.5)
-		cfg.SevereOverbought = clamp01((1.0 + ob) / 2)
-	}
 
 	// ---------- 1) 分层打分 ----------
-	layerScore := 0.0
 	switch {
 	case s < cfg.SevereOversold:
-		layerScore += cfg.WSevereOS
-		res.Signals = append(res.Signals, "StochRSI严重超卖")
+		hit(&res, "layer", "StochRSI严重超卖", cfg.WSevereOS)
 	case s >= cfg.SevereOversold && s < os:
-		layerScore += cfg.WOS
-		res.Signals = append(res.Signals, "StochRSI轻度超卖")
+		hit(&res, "layer", "StochRSI轻度超卖", cfg.WOS)
 	case s > ob && s <= cfg.SevereOverbought:
-		layerScore += cfg.WOB
-		res.Signals = append(res.Signals, "StochRSI轻度超买")
+		hit(&res, "layer", "StochRSI轻度超买", cfg.WOB)
 	case s > cfg.SevereOverbought:
-		layerScore += cfg.WSevereOB
-		res.Signals = append(res.Signals, "StochRSI严重超买")
+		hit(&res, "layer", "StochRSI严重超买", cfg.WSevereOB)
 	}
-	acc(&res, "layer", layerScore)
 
 	// ---------- 2) 底部抬升 ----------
 	if s < os {
 		rising := isRising(series, index, cfg.MinRiseBars)
 		if rising && slope(series, index, cfg.SlopeLookback) > 0 {
-			acc(&res, "bottom_rise", 1.0)
-			res.Signals = append(res.Signals, "StochRSI底部回升")
+			hit(&res, "bottom_rise", "StochRSI底部回升", 1.0)
 		}
 	}
 
 	// ---------- 3) 金叉/死叉 ----------
-	// series 版无 K/D 数据，此处直接跳过
+	// series 版无 K/D 数据，由 EvaluateStochRSIEngine 调用 EvaluateStochKDCross
 
 	// ---------- 4) 极值持续 ----------
 	if cfg.PersistBars > 0 {
 		if stayedBelow(series, index, os, cfg.PersistBars) {
-			acc(&res, "persist_os", cfg.WPersistOS)
-			res.Signals = append(res.Signals, "StochRSI超卖持续")
+			hit(&res, "persist_os", "StochRSI超卖持续", cfg.WPersistOS)
 		}
 		if stayedAbove(series, index, ob, cfg.PersistBars) {
-			acc(&res, "persist_ob", cfg.WPersistOB)
-			res.Signals = append(res.Signals, "StochRSI超买持续")
+			hit(&res, "persist_ob", "StochRSI超买持续", cfg.WPersistOB)
 		}
 	}
 
@@ -199,6 +184,120 @@ func EvaluateStochRSISignals(series []float64, index int, cfg StochRSIConfig) (E
 	return res, nil
 }
 
+// resolveStochRSIBands 启用百分位阈值且窗口内有效样本不少于 PercentileMinHistory 时，
+// 用近 PercentileWindow 根的分位数替换 Oversold / Overbought 并据此调整严重超卖 / 超买；否则原样返回
+func resolveStochRSIBands(series []float64, index int, cfg StochRSIConfig) StochRSIConfig {
+	if !cfg.UsePercentile || cfg.PercentileWindow <= 10 || index < 0 || index >= len(series) {
+		return cfg
+	}
+	window := finiteValues(series[max(0, index-cfg.PercentileWindow+1) : index+1])
+	if len(window) < max(cfg.PercentileMinHistory, 2) {
+		return cfg
+	}
+
+	percentile := cfg.PercentileFunc
+	if percentile == nil {
+		percentile = PercentileLinear
+	}
+	cfg.Oversold = percentile(window, cfg.POversold)
+	cfg.Overbought = percentile(window, cfg.POverbought)
+
+	// 动态调整严重超卖/超买
+	cfg.SevereOversold = clamp01(cfg.Oversold * 0.5)
+	cfg.SevereOverbought = clamp01((1.0 + cfg.Overbought) / 2)
+	return cfg
+}
+
+// EvaluateStochRSIEngine 单 series 评分 + 多周期共振：
+// 本周期处于超卖/超买分层，且高周期也低于超卖 / 高于超买阈值时附加 WMTFOS / WMTFOB；
+// 启用百分位阈值时 K/D 交叉与共振判断均使用本周期的同一组动态阈值
+func EvaluateStochRSIEngine(eng Engine, index int, cfg StochRSIConfig) (EvalResult, error) {
+	res, err := EvaluateStochRSISignals(eng.StochRSI, index, cfg)
+	if err != nil {
+		return res, err
+	}
+
+	// K/D 交叉与多周期共振使用与本周期分层相同的（可能是动态的）超卖 / 超买阈值
+	bands := resolveStochRSIBands(eng.StochRSI, index, cfg)
+
+	if cfg.EnableCrossBoost && len(eng.StochK) > 0 {
+		cross, err := EvaluateStochKDCross(eng.StochK, eng.StochD, index, bands)
+		if err != nil {
+			return res, err
+		}
+		for _, s := range cross.Signals {
+			hit(&res, "cross", s, cross.SignalScores[s])
+		}
+		res.Score = sumComponents(res.Components)
+	}
+
+	if cfg.EnableMTF && index < len(eng.StochRSIHTF) {
+		htf := eng.StochRSIHTF[index] // NaN 时下面的比较均为 false
+		layer := res.Components["layer"]
+		switch {
+		case layer > 0 && htf < bands.Oversold:
+			hit(&res, "mtf_os", "StochRSI多周期共振超卖", cfg.WMTFOS)
+		case layer < 0 && htf > bands.Overbought:
+			hit(&res, "mtf_ob", "StochRSI多周期共振超买", cfg.WMTFOB)
+		}
+		res.Score = sumComponents(res.Components)
+	}
+	return res, nil
+}
+
+/*
+EvaluateStochKDCross StochRSI %K/%D 交叉评分：
+  - 超卖区金叉：%K 上穿 %D，交叉发生时 %K 或 %D 低于 Oversold → WCrossUpInOS
+  - 超买区死叉：%K 下穿 %D，交叉发生时 %K 或 %D 高于 Overbought → WCrossDownInOB
+  - CrossoverHysteresis = N：交叉后连续 N 根保持同侧才在第 N 根确认，过滤来回缠绕
+  - 区域外的交叉只记信号不计分
+*/
+func EvaluateStochKDCross(k, d []float64, index int, cfg StochRSIConfig) (EvalResult, error) {
+	res := EvalResult{
+		Score:      0,
+		Signals:    []string{},
+		Components: map[string]float64{},
+	}
+	if len(k) != len(d) || index < 0 || index >= len(k) {
+		return res, errors.New("invalid K/D series or index")
+	}
+
+	h := max(1, cfg.CrossoverHysteresis)
+	at := index - h + 1 // 交叉发生的 bar
+	if at < 1 {
+		return res, nil
+	}
+
+	scale := 1.0
+	if cfg.NormalizeKD && (maxFloat(k[at-1:index+1]) > 1 || maxFloat(d[at-1:index+1]) > 1) {
+		scale = 100
+	}
+	kv := func(i int) float64 { return k[i] / scale }
+	dv := func(i int) float64 { return d[i] / scale }
+
+	above, below := true, true
+	for i := at; i <= index; i++ {
+		above = above && kv(i) > dv(i)
+		below = below && kv(i) < dv(i)
+	}
+	crossUp := above && kv(at-1) <= dv(at-1)
+	crossDown := below && kv(at-1) >= dv(at-1)
+
+	switch {
+	case crossUp && math.Min(kv(at), dv(at)) < cfg.Oversold:
+		hit(&res, "cross_up_os", "StochRSI超卖区金叉", cfg.WCrossUpInOS)
+	case crossUp:
+		hit(&res, "cross_up", "StochRSI金叉", 0)
+	case crossDown && math.Max(kv(at), dv(at)) > cfg.Overbought:
+		hit(&res, "cross_down_ob", "StochRSI超买区死叉", cfg.WCrossDownInOB)
+	case crossDown:
+		hit(&res, "cross_down", "StochRSI死叉", 0)
+	}
+
+	res.Score = sumComponents(res.Components)
+	return res, nil
+}
+
 // =======================================================
 // 工具函数
 // =======================================================
@@ -265,6 +364,16 @@ func acc(res *EvalResult, key string, v float64) {
 	res.Components[key] += v
 }
 
+// 记录命中信号：累加子项分数、追加信号描述并记录该信号的得分
+func hit(res *EvalResult, key, signal string, v float64) {
+	acc(res, key, v)
+	res.Signals = append(res.Signals, signal)
+	if res.SignalScores == nil {
+		res.SignalScores = map[string]float64{}
+	}
+	res.SignalScores[signal] += v
+}
+
 // 汇总总分
 func sumComponents(m map[string]float64) float64 {
 	sum := 0.0
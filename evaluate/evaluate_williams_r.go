@@ -0,0 +1,48 @@
+package evaluate
+
+// =======================================================
+// Williams %R 评分（取值 -100 ~ 0）
+// =======================================================
+
+type WilliamsRConfig struct {
+	Bands      Bands           // 固定阈值（默认 -95 / -80 / -20 / -5）
+	Percentile PercentileBands // 启用后按近期 %R 分位数动态计算阈值
+
+	WSevereOS, WOS float64 // 严重 / 轻度超卖加分
+	WOB, WSevereOB float64 // 轻度 / 严重超买扣分
+}
+
+func DefaultWilliamsRConfig() WilliamsRConfig {
+	return WilliamsRConfig{
+		Bands:      Bands{SevereLow: -95, Low: -80, High: -20, SevereHigh: -5},
+		Percentile: DefaultPercentileBands(),
+		WSevereOS:  2, WOS: 1,
+		WOB: -1, WSevereOB: -2,
+	}
+}
+
+func EvaluateWilliamsRSignals(wr []float64, idx int, cfg WilliamsRConfig) (EvalResult, error) {
+	res := EvalResult{
+		Score:      0,
+		Signals:    []string{},
+		Components: map[string]float64{},
+	}
+	if idx < 0 || idx >= len(wr) {
+		return res, ErrIndexOutOfRange
+	}
+
+	v := wr[idx]
+	b, _ := cfg.Percentile.Resolve(wr, idx, cfg.Bands)
+	switch {
+	case v < b.SevereLow:
+		hit(&res, "wr_severe_os", "威廉指标严重超卖", cfg.WSevereOS)
+	case v >= b.SevereLow && v < b.Low:
+		hit(&res, "wr_os", "威廉指标超卖", cfg.WOS)
+	case v > b.High && v <= b.SevereHigh:
+		hit(&res, "wr_ob", "威廉指标超买", cfg.WOB)
+	case v > b.SevereHigh:
+		hit(&res, "wr_severe_ob", "威廉指标严重超买", cfg.WSevereOB)
+	}
+	res.Score = sumComponents(res.Components)
+	return res, nil
+}
@@ -0,0 +1,132 @@
+package evaluate
+
+import (
+	"errors"
+	"math"
+)
+
+// =======================================================
+// Evaluator：可插拔的单指标评分器
+// =======================================================
+
+// Evaluator 对某一指标序列在 index 处评分；Warmup 之前的 bar 调用方应跳过
+type Evaluator interface {
+	Name() string
+	Warmup() int
+	Evaluate(index int) (EvalResult, error)
+}
+
+// ErrNotReady index 处的指标值仍在预热期（NaN），调用方应跳过该指标而非视为错误
+var ErrNotReady = errors.New("indicator not ready")
+
+// NewEvalResult 空结果，配合 Hit 使用
+func NewEvalResult() EvalResult {
+	return EvalResult{Signals: []string{}, Components: map[string]float64{}}
+}
+
+// Hit 记录一个命中信号并同步总分，供包外的 Evaluator 使用
+func (r *EvalResult) Hit(key, signal string, v float64) {
+	hit(r, key, signal, v)
+	r.Score = sumComponents(r.Components)
+}
+
+// ---- 本包评分函数对应的 Evaluator ----
+
+type RSIEvaluator struct {
+	RSI        []float64
+	Config     RSIConfig
+	WarmupBars int // 通常为 RSI 周期
+}
+
+func (e RSIEvaluator) Name() string { return "rsi" }
+func (e RSIEvaluator) Warmup() int  { return e.WarmupBars }
+func (e RSIEvaluator) Evaluate(index int) (EvalResult, error) {
+	if notReady(e.RSI, index) {
+		return NewEvalResult(), ErrNotReady
+	}
+	return EvaluateRSISignalsWithConfig(e.RSI, index, e.Config)
+}
+
+type StochRSIEvaluator struct {
+	Engine     Engine
+	Config     StochRSIConfig
+	WarmupBars int // 通常为 2 倍 RSI 周期
+}
+
+func (e StochRSIEvaluator) Name() string { return "stoch_rsi" }
+func (e StochRSIEvaluator) Warmup() int {
+	return max(e.WarmupBars, 1+max(1+e.Config.SlopeLookback, max(e.Config.MinRiseBars, e.Config.CrossoverHysteresis)))
+}
+func (e StochRSIEvaluator) Evaluate(index int) (EvalResult, error) {
+	if notReady(e.Engine.StochRSI, index) {
+		return NewEvalResult(), ErrNotReady
+	}
+	return EvaluateStochRSIEngine(e.Engine, index, e.Config)
+}
+
+type CCIEvaluator struct {
+	CCI        []float64
+	Config     CCIConfig
+	WarmupBars int
+}
+
+func (e CCIEvaluator) Name() string { return "cci" }
+func (e CCIEvaluator) Warmup() int  { return e.WarmupBars }
+func (e CCIEvaluator) Evaluate(index int) (EvalResult, error) {
+	if notReady(e.CCI, index) {
+		return NewEvalResult(), ErrNotReady
+	}
+	return EvaluateCCISignals(e.CCI, index, e.Config)
+}
+
+type KDJEvaluator struct {
+	KDJ        KDJSeries
+	Prices     PriceSeries
+	Config     KDJConfig
+	WarmupBars int
+}
+
+func (e KDJEvaluator) Name() string { return "kdj" }
+func (e KDJEvaluator) Warmup() int  { return e.WarmupBars }
+func (e KDJEvaluator) Evaluate(index int) (EvalResult, error) {
+	if index >= 0 && index < e.KDJ.Len() && math.IsNaN(e.KDJ.J(index)) {
+		return NewEvalResult(), ErrNotReady
+	}
+	return EvaluateKDJ(e.KDJ, e.Prices, index, e.Config)
+}
+
+type WilliamsREvaluator struct {
+	WilliamsR  []float64
+	Config     WilliamsRConfig
+	WarmupBars int
+}
+
+func (e WilliamsREvaluator) Name() string { return "williams_r" }
+func (e WilliamsREvaluator) Warmup() int  { return e.WarmupBars }
+func (e WilliamsREvaluator) Evaluate(index int) (EvalResult, error) {
+	if notReady(e.WilliamsR, index) {
+		return NewEvalResult(), ErrNotReady
+	}
+	return EvaluateWilliamsRSignals(e.WilliamsR, index, e.Config)
+}
+
+type IchimokuEvaluator struct {
+	Lines      IchimokuLines
+	Closes     []float64
+	Config     IchimokuConfig
+	WarmupBars int // 转换线 / 基准线就绪即可评分，云层等其余各项未就绪时单独跳过
+}
+
+func (e IchimokuEvaluator) Name() string { return "ichimoku" }
+func (e IchimokuEvaluator) Warmup() int  { return e.WarmupBars }
+func (e IchimokuEvaluator) Evaluate(index int) (EvalResult, error) {
+	if notReady(e.Lines.Tenkan, index) || notReady(e.Lines.Kijun, index) {
+		return NewEvalResult(), ErrNotReady
+	}
+	return EvaluateIchimoku(e.Lines, e.Closes, index, e.Config)
+}
+
+// notReady index 在序列范围内且值为 NaN；越界交由各评分函数自行报错
+func notReady(series []float64, index int) bool {
+	return index >= 0 && index < len(series) && math.IsNaN(series[index])
+}
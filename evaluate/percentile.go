@@ -0,0 +1,69 @@
+package evaluate
+
+import "math"
+
+// =======================================================
+// 百分位动态阈值（RSI / CCI / KDJ / Williams %R 共用）
+// =======================================================
+
+// Bands 振荡指标的四档阈值：严重超卖 < 超卖 < 超买 < 严重超买
+type Bands struct {
+	SevereLow  float64
+	Low        float64
+	High       float64
+	SevereHigh float64
+}
+
+// PercentileBands 用指标自身近 Window 根的分位数代替固定阈值，
+// 成交清淡、长期偏离常规区间的股票可以按自身历史校准
+type PercentileBands struct {
+	Enabled    bool
+	Window     int     // 回看窗口（含当前 bar）
+	MinHistory int     // 窗口内有效样本少于此数时退回固定阈值
+	SevereLow  float64 // 严重超卖分位（默认 0.05）
+	Low        float64 // 超卖分位（默认 0.20）
+	High       float64 // 超买分位（默认 0.80）
+	SevereHigh float64 // 严重超买分位（默认 0.95）
+}
+
+func DefaultPercentileBands() PercentileBands {
+	return PercentileBands{
+		Enabled:    false,
+		Window:     250,
+		MinHistory: 60,
+		SevereLow:  0.05,
+		Low:        0.20,
+		High:       0.80,
+		SevereHigh: 0.95,
+	}
+}
+
+// Resolve 返回 index 处使用的阈值，未启用或样本不足时返回 fixed；第二个返回值表示是否使用了动态阈值
+func (p PercentileBands) Resolve(series []float64, index int, fixed Bands) (Bands, bool) {
+	if !p.Enabled || p.Window <= 1 || index < 0 || index >= len(series) {
+		return fixed, false
+	}
+
+	window := finiteValues(series[max(0, index-p.Window+1) : index+1])
+	if len(window) < max(p.MinHistory, 2) {
+		return fixed, false
+	}
+
+	return Bands{
+		SevereLow:  PercentileLinear(window, p.SevereLow),
+		Low:        PercentileLinear(window, p.Low),
+		High:       PercentileLinear(window, p.High),
+		SevereHigh: PercentileLinear(window, p.SevereHigh),
+	}, true
+}
+
+// finiteValues 过滤掉 NaN / Inf（预热期的无效值），返回新切片
+func finiteValues(series []float64) []float64 {
+	out := make([]float64, 0, len(series))
+	for _, v := range series {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"errors"
 	"fmt"
 	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
@@ -9,8 +8,6 @@ import (
 	"os"
 	"wolf_street/model"
 	"wolf_street/pkginit"
-	"wolf_street/service"
-	"wolf_street/util"
 )
 
 type DataPoint struct {
@@ -217,75 +214,27 @@ func main() {
 	app := &cli.App{
 		Name:  "Stock Strategy CLI",
 		Usage: "Choose strategy and stock to execute backtest",
-		Action: func(c *cli.Context) error {
-			/* Step 1: Strategy Selection */
-			selectedStrategy, err := util.CliMenuSelectStrategy(15)
-			if err != nil {
-				pkginit.Logger.Error("Strategy selection failed", zap.Error(err))
-				return err
-			}
-
-			var stock model.Stock
-			var candles []service.Candle
-
-			// Step 2 & 3: Stock Selection + Load Candle Data Loop
-			for {
-				stock, err = util.CliMenuSelectStock(10)
-				if err != nil {
-					pkginit.Logger.Error("Stock selection failed", zap.Error(err))
-					return err
-				}
-
-				candles, err = util.LoadCandleData(stock.Code, stock.Number)
-				if err != nil {
-
-					pkginit.Logger.Error("LoadCandleData", zap.Error(err))
-
-					// 文件不存在：提示用户重新选股，不退出
-					if errors.Is(err, os.ErrNotExist) {
-						fmt.Printf("Data file for %s (%s) not found. Please select another stock.\n\n", stock.Name, stock.Code)
-						continue // 重新回到股票选择
-					}
-
-					// 其他文件异常则退出
-					pkginit.Logger.Error("Failed to load candle data", zap.Error(err))
-					return err
-				}
-
-				if len(candles) == 0 {
-					return fmt.Errorf("candles 数据为空")
-				}
-				if len(candles) < 30 {
-					return fmt.Errorf("candles 数据不足, 至少需要30根K线")
-				}
-
-				// 成功加载Candle数据，退出循环
-				break
-			}
-
-			// Step 4: Execute Strategy (Placeholder Logic)
-			switch selectedStrategy.ID {
-			case 1:
-				err := service.StrategyScoringEngine(candles)
-				//result = service.StrategyRSIBollinger(candles)
-				if err != nil {
-					pkginit.Logger.Error("Strategy failed:", zap.Any("Strategy", selectedStrategy.Name), zap.Error(err))
-				}
-			case 2:
-				// result = service.StrategyMACross(candles)
-			default:
-				pkginit.Logger.Error("Strategy not implemented yet")
-				return nil
-			}
-
-			// Placeholder result output
-
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "universe", Value: model.UniversePath, Usage: "stock universe file (yaml / json / csv)"},
+			&cli.StringFlag{Name: "sector", Usage: "only offer stocks in this sector in the interactive menu"},
+		},
+		Before: func(c *cli.Context) error {
+			model.UniversePath = c.String("universe")
 			return nil
 		},
+		Commands: []*cli.Command{
+			backtestCommand(),
+			listStocksCommand(),
+			listStrategiesCommand(),
+			listEvaluatorsCommand(),
+		},
+		// 无子命令时进入交互菜单
+		Action: interactiveAction,
 	}
 
 	err := app.Run(os.Args)
 	if err != nil {
 		pkginit.Logger.Error("Main error", zap.Error(err))
+		os.Exit(1)
 	}
 }
@@ -1,39 +1,216 @@
 package model
 
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type Board string
+
+const (
+	BoardMain Board = "Main"
+	BoardACE  Board = "ACE"
+	BoardLEAP Board = "LEAP"
+)
+
 type Stock struct {
-	Name        string
-	Code        string
-	Number      string
-	Description string
+	Name        string `yaml:"name" json:"name"`
+	Code        string `yaml:"code" json:"code"`
+	Number      string `yaml:"number" json:"number"`
+	Description string `yaml:"description" json:"description"`
+	Board       Board  `yaml:"board" json:"board"`
+	Sector      string `yaml:"sector" json:"sector"`
+	Currency    string `yaml:"currency" json:"currency"`
+	DataFile    string `yaml:"data_file" json:"data_file"`       // 为空时使用默认路径
+	ActionsFile string `yaml:"actions_file" json:"actions_file"` // 公司行动文件，为空时使用默认路径（可不存在）
+
+	// AdaptiveThresholds 振荡指标使用自身历史分位数阈值，适合成交清淡、指标长期偏离常规区间的股票
+	AdaptiveThresholds bool `yaml:"adaptive_thresholds" json:"adaptive_thresholds"`
+}
+
+// DataPath K 线数据文件路径
+func (s Stock) DataPath() string {
+	if s.DataFile != "" {
+		return s.DataFile
+	}
+	return "./data_set/" + s.Code + "_" + s.Number + "_data.csv"
+}
+
+// ActionsPath 公司行动（拆股 / 红股 / 附加股 / 股息）文件路径
+func (s Stock) ActionsPath() string {
+	if s.ActionsFile != "" {
+		return s.ActionsFile
+	}
+	return "./data_set/" + s.Code + "_" + s.Number + "_actions.csv"
 }
 
+// UniversePath 股票池配置文件，可由命令行 --universe 覆盖
+var UniversePath = "config/universe.yaml"
+
 func GetAllStock() ([]Stock, error) {
-	var Stocks = []Stock{
-		{
-			Name:        "AuMas Resources Bhd",
-			Code:        "AUMAS",
-			Number:      "0098",
-			Description: "Investment holding company & segments include Aquaculture operations",
-		},
-		{
-			Name:        "MN Holdings Bhd",
-			Code:        "MNHLDG",
-			Number:      "0245",
-			Description: "Infrastructure utilities construction industries",
-		},
-		{
-			Name:        "Pharmaniaga Bhd",
-			Code:        "PHARMA",
-			Number:      "7081",
-			Description: "R&D, manufacturing of generic pharmaceutical products",
-		},
-		{
-			Name:        "Zetrix AI Bhd",
-			Code:        "ZETRIX",
-			Number:      "0138",
-			Description: "myeg",
-		},
-	}
-
-	return Stocks, nil
+	return LoadUniverse(UniversePath)
+}
+
+// GetStocksBySector 按板块过滤（不区分大小写），sector 为空返回全部
+func GetStocksBySector(sector string) ([]Stock, error) {
+	stocks, err := GetAllStock()
+	if err != nil {
+		return nil, err
+	}
+	return FilterBySector(stocks, sector), nil
+}
+
+func FilterBySector(stocks []Stock, sector string) []Stock {
+	if sector == "" {
+		return stocks
+	}
+	var out []Stock
+	for _, s := range stocks {
+		if strings.EqualFold(s.Sector, sector) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// FindStock 按股票代码（Code）或编号（Number）查找
+func FindStock(codeOrNumber string) (Stock, error) {
+	stocks, err := GetAllStock()
+	if err != nil {
+		return Stock{}, err
+	}
+	for _, s := range stocks {
+		if strings.EqualFold(s.Code, codeOrNumber) || s.Number == codeOrNumber {
+			return s, nil
+		}
+	}
+	return Stock{}, fmt.Errorf("stock %q not found", codeOrNumber)
+}
+
+// LoadUniverse 按扩展名读取 YAML / JSON / CSV 股票池并校验
+func LoadUniverse(path string) ([]Stock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read universe %s: %w", path, err)
+	}
+
+	var stocks []Stock
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		var doc struct {
+			Stocks []Stock `yaml:"stocks"`
+		}
+		err = yaml.Unmarshal(data, &doc)
+		stocks = doc.Stocks
+	case ".json":
+		var doc struct {
+			Stocks []Stock `json:"stocks"`
+		}
+		err = json.Unmarshal(data, &doc)
+		stocks = doc.Stocks
+	case ".csv":
+		stocks, err = parseUniverseCSV(data)
+	default:
+		return nil, fmt.Errorf("unsupported universe format %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse universe %s: %w", path, err)
+	}
+
+	if err := ValidateUniverse(stocks); err != nil {
+		return nil, fmt.Errorf("invalid universe %s: %w", path, err)
+	}
+	return stocks, nil
+}
+
+// parseUniverseCSV 首行为表头，列名与 YAML 字段一致，顺序不限
+func parseUniverseCSV(data []byte) ([]Stock, error) {
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	col := map[string]int{}
+	for i, h := range records[0] {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	get := func(rec []string, name string) string {
+		if i, ok := col[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	var stocks []Stock
+	for _, rec := range records[1:] {
+		stocks = append(stocks, Stock{
+			Name:        get(rec, "name"),
+			Code:        get(rec, "code"),
+			Number:      get(rec, "number"),
+			Description: get(rec, "description"),
+			Board:       Board(get(rec, "board")),
+			Sector:      get(rec, "sector"),
+			Currency:    get(rec, "currency"),
+			DataFile:    get(rec, "data_file"),
+			ActionsFile: get(rec, "actions_file"),
+
+			AdaptiveThresholds: strings.EqualFold(get(rec, "adaptive_thresholds"), "true"),
+		})
+	}
+	return stocks, nil
+}
+
+// ValidateUniverse 校验必填字段、板块、重复代码以及显式配置的数据文件，返回全部问题
+func ValidateUniverse(stocks []Stock) error {
+	if len(stocks) == 0 {
+		return errors.New("no stocks defined")
+	}
+
+	var errs []error
+	seen := map[string]int{}
+	for i, s := range stocks {
+		where := fmt.Sprintf("stock #%d (%s)", i+1, s.Code)
+		if s.Code == "" {
+			errs = append(errs, fmt.Errorf("%s: code is empty", where))
+		}
+		if s.Number == "" {
+			errs = append(errs, fmt.Errorf("%s: number is empty", where))
+		}
+		if s.Name == "" {
+			errs = append(errs, fmt.Errorf("%s: name is empty", where))
+		}
+		switch s.Board {
+		case BoardMain, BoardACE, BoardLEAP, "":
+		default:
+			errs = append(errs, fmt.Errorf("%s: unknown board %q", where, s.Board))
+		}
+
+		code := strings.ToUpper(s.Code)
+		if j, ok := seen[code]; ok && code != "" {
+			errs = append(errs, fmt.Errorf("%s: duplicate code, first defined at stock #%d", where, j+1))
+		} else {
+			seen[code] = i
+		}
+
+		if s.DataFile != "" {
+			if _, err := os.Stat(s.DataFile); err != nil {
+				errs = append(errs, fmt.Errorf("%s: data file %s: %w", where, s.DataFile, err))
+			}
+		}
+		if s.ActionsFile != "" {
+			if _, err := os.Stat(s.ActionsFile); err != nil {
+				errs = append(errs, fmt.Errorf("%s: actions file %s: %w", where, s.ActionsFile, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
 }
@@ -1,7 +1,14 @@
 package model
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 type Strategy struct {
 	ID          int
+	Key         string // 命令行使用的名称，如 "scoring"
 	Name        string
 	Description string
 	Category    string // e.g., "Trend Following", "Momentum", "Arbitrage"
@@ -27,8 +34,8 @@ type Strategy struct {
 func GetAllStrategy() ([]Strategy, error) {
 	var Strategies = []Strategy{
 
-		{ID: 1, Name: "StrategyScoringEngine", Category: "StrategyScoringEngine", Description: "StrategyScoringEngine"},
-		{ID: 2, Name: "RSI + Bollinger Band", Category: "Mean Reversion", Description: "Combine RSI oversold/overbought with Bollinger Bands."},
+		{ID: 1, Key: "scoring", Name: "StrategyScoringEngine", Category: "StrategyScoringEngine", Description: "StrategyScoringEngine"},
+		{ID: 2, Key: "rsi-bollinger", Name: "RSI + Bollinger Band", Category: "Mean Reversion", Description: "Combine RSI oversold/overbought with Bollinger Bands."},
 		//{ID: 3, Name: "MA Cross (Golden/Death Cross)", Category: "Trend Following", Description: "50-day MA crossing 200-day MA."},
 		//{ID: 4, Name: "MACD Cross Strategy", Category: "Momentum", Description: "Trade on MACD line crossovers."},
 		//{ID: 5, Name: "Breakout Momentum", Category: "Momentum", Description: "Trade breakout patterns with volume confirmation."},
@@ -43,3 +50,17 @@ func GetAllStrategy() ([]Strategy, error) {
 
 	return Strategies, nil
 }
+
+// FindStrategy 按 Key 或 ID 查找策略
+func FindStrategy(keyOrID string) (Strategy, error) {
+	strategies, err := GetAllStrategy()
+	if err != nil {
+		return Strategy{}, err
+	}
+	for _, s := range strategies {
+		if strings.EqualFold(s.Key, keyOrID) || strconv.Itoa(s.ID) == keyOrID {
+			return s, nil
+		}
+	}
+	return Strategy{}, fmt.Errorf("strategy %q not found", keyOrID)
+}
@@ -0,0 +1,192 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"wolf_street/backtest"
+	"wolf_street/service"
+)
+
+const chartWidth = 1200
+
+// overlays 价格图上的叠加指标
+type overlays struct {
+	Bollinger service.BollingerBand
+	KC        service.KC
+	SAR       []float64
+}
+
+func computeOverlays(candles []service.Candle) overlays {
+	highs := make([]float64, len(candles))
+	lows := make([]float64, len(candles))
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		highs[i], lows[i], closes[i] = c.High, c.Low, c.Close
+	}
+
+	return overlays{
+		Bollinger: service.CalculateBollinger(closes, 20),
+		KC:        service.CalculateKeltnerChannel(highs, lows, closes, 20),
+		SAR:       service.CalculateSAR(highs, lows, 0.02, 0.2),
+	}
+}
+
+func dates(candles []service.Candle) []string {
+	out := make([]string, len(candles))
+	for i, c := range candles {
+		out[i] = c.Date
+	}
+	return out
+}
+
+// priceChart K 线 + 布林带 / Keltner / SAR 叠加 + 买卖点
+func priceChart(candles []service.Candle, ov overlays, fills []backtest.Fill) string {
+	highs := make([]float64, len(candles))
+	lows := make([]float64, len(candles))
+	for i, c := range candles {
+		highs[i], lows[i] = c.High, c.Low
+	}
+	lo, hi := bounds(highs, lows, ov.Bollinger.UpperBand, ov.Bollinger.LowerBand)
+
+	f := newFrame(chartWidth, 460, len(candles), lo, hi)
+	var b strings.Builder
+	f.open(&b, dates(candles), "%.3f")
+
+	bodyW := math.Max(1, f.step()*0.6)
+	for i, c := range candles {
+		color := "#26a69a"
+		if c.Close < c.Open {
+			color = "#ef5350"
+		}
+		x := f.x(i)
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"/>`, x, f.y(c.High), x, f.y(c.Low), color)
+		top, bottom := f.y(math.Max(c.Open, c.Close)), f.y(math.Min(c.Open, c.Close))
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`, x-bodyW/2, top, bodyW, math.Max(bottom-top, 0.5), color)
+	}
+
+	f.polyline(&b, ov.Bollinger.UpperBand, "#1e88e5", 1, "")
+	f.polyline(&b, ov.Bollinger.MidBand, "#1e88e5", 1, "2 2")
+	f.polyline(&b, ov.Bollinger.LowerBand, "#1e88e5", 1, "")
+	f.polyline(&b, ov.KC.UpperBand, "#8e24aa", 1, "5 3")
+	f.polyline(&b, ov.KC.LowerBand, "#8e24aa", 1, "5 3")
+
+	for i, v := range ov.SAR {
+		if !valid(v) || v < f.min || v > f.max {
+			continue
+		}
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="1.6" fill="#ff9800"/>`, f.x(i), f.y(v))
+	}
+
+	for _, fill := range fills {
+		if fill.Index < 0 || fill.Index >= len(candles) {
+			continue
+		}
+		x, y := f.x(fill.Index), f.y(fill.Price)
+		title := escape(fmt.Sprintf("%s %s %.0f @ %.4f %s", fill.Date, fill.Side, fill.Qty, fill.Price, fill.Reason))
+		if fill.Side == "BUY" {
+			fmt.Fprintf(&b, `<path d="M%.1f %.1f l-6 10 h12 z" fill="#2e7d32"><title>%s</title></path>`, x, y+2, title)
+		} else {
+			fmt.Fprintf(&b, `<path d="M%.1f %.1f l-6 -10 h12 z" fill="#c62828"><title>%s</title></path>`, x, y-2, title)
+		}
+	}
+
+	legend(&b, f, []legendItem{
+		{"Bollinger", "#1e88e5"}, {"Keltner", "#8e24aa"}, {"SAR", "#ff9800"}, {"BUY", "#2e7d32"}, {"SELL", "#c62828"},
+	})
+	f.close(&b)
+	return b.String()
+}
+
+// scoreChart ScoringEngine 评分柱状图
+func scoreChart(candles []service.Candle, scores []float64) string {
+	lo, hi := bounds(scores)
+	lo, hi = math.Min(lo, 0), math.Max(hi, 0)
+
+	f := newFrame(chartWidth, 180, len(scores), lo, hi)
+	var b strings.Builder
+	f.open(&b, dates(candles), "%.0f")
+
+	barW := math.Max(1, f.step()*0.7)
+	zero := f.y(0)
+	for i, s := range scores {
+		if s == 0 || math.IsNaN(s) {
+			continue
+		}
+		color := "#26a69a"
+		if s < 0 {
+			color = "#ef5350"
+		}
+		y := f.y(s)
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`,
+			f.x(i)-barW/2, math.Min(y, zero), barW, math.Abs(zero-y), color)
+	}
+	f.hline(&b, 0, "#999")
+	f.close(&b)
+	return b.String()
+}
+
+// equityChart 权益曲线
+func equityChart(equity []backtest.EquityPoint, initial float64) string {
+	values := make([]float64, len(equity))
+	labels := make([]string, len(equity))
+	for i, p := range equity {
+		values[i] = p.Equity
+		labels[i] = p.Date
+	}
+	lo, hi := bounds(values, []float64{initial})
+
+	f := newFrame(chartWidth, 260, len(values), lo, hi)
+	var b strings.Builder
+	f.open(&b, labels, "%.0f")
+	f.hline(&b, initial, "#999")
+	f.polyline(&b, values, "#1e88e5", 1.5, "")
+	f.close(&b)
+	return b.String()
+}
+
+// drawdownChart 回撤面积图（百分比，0 在顶部）
+func drawdownChart(equity []backtest.EquityPoint, initial float64) string {
+	dd := make([]float64, len(equity))
+	labels := make([]string, len(equity))
+	peak := initial
+	lo := 0.0
+	for i, p := range equity {
+		peak = math.Max(peak, p.Equity)
+		if peak > 0 {
+			dd[i] = (p.Equity - peak) / peak * 100
+		}
+		lo = math.Min(lo, dd[i])
+		labels[i] = p.Date
+	}
+
+	f := newFrame(chartWidth, 160, len(dd), lo, 0)
+	var b strings.Builder
+	f.open(&b, labels, "%.1f%%")
+
+	if len(dd) > 0 {
+		var pts []string
+		pts = append(pts, fmt.Sprintf("%.1f,%.1f", f.x(0), f.y(0)))
+		for i, v := range dd {
+			pts = append(pts, fmt.Sprintf("%.1f,%.1f", f.x(i), f.y(v)))
+		}
+		pts = append(pts, fmt.Sprintf("%.1f,%.1f", f.x(len(dd)-1), f.y(0)))
+		fmt.Fprintf(&b, `<polygon fill="#ef5350" fill-opacity="0.35" stroke="#ef5350" points="%s"/>`, strings.Join(pts, " "))
+	}
+	f.hline(&b, 0, "#999")
+	f.close(&b)
+	return b.String()
+}
+
+type legendItem struct {
+	label, color string
+}
+
+func legend(b *strings.Builder, f frame, items []legendItem) {
+	x := f.padL + 8
+	for _, it := range items {
+		fmt.Fprintf(b, `<rect x="%.1f" y="%.1f" width="10" height="10" fill="%s"/>`, x, f.padT+6, it.color)
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" font-size="11" fill="#333">%s</text>`, x+14, f.padT+15, escape(it.label))
+		x += 14 + float64(len(it.label))*7 + 12
+	}
+}
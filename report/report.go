@@ -0,0 +1,127 @@
+// Package report 生成单文件 HTML 回测报告，图表为服务端生成的 SVG，
+// 不依赖任何外部 JS / CDN，可离线分享。
+package report
+
+import (
+	"errors"
+	"html/template"
+	"os"
+	"path/filepath"
+	"wolf_street/backtest"
+	"wolf_street/service"
+)
+
+// Input 生成报告所需数据
+type Input struct {
+	Title   string
+	Candles []service.Candle
+	Result  *backtest.Result
+	Engine  *service.ScoringEngine // 可选：提供时绘制每根 bar 的评分
+}
+
+type page struct {
+	Title    string
+	Result   *backtest.Result
+	Metrics  []backtest.MetricRow
+	Price    template.HTML
+	Score    template.HTML
+	Equity   template.HTML
+	Drawdown template.HTML
+}
+
+// WriteHTML 生成报告并写入 path
+func WriteHTML(path string, in Input) error {
+	if in.Result == nil {
+		return errors.New("report: result is nil")
+	}
+	if len(in.Candles) == 0 {
+		return errors.New("report: candles 数据为空")
+	}
+
+	p := page{
+		Title:    in.Title,
+		Result:   in.Result,
+		Metrics:  in.Result.Metrics.Rows(),
+		Price:    template.HTML(priceChart(in.Candles, computeOverlays(in.Candles), in.Result.Fills)),
+		Equity:   template.HTML(equityChart(in.Result.Equity, in.Result.InitialCash)),
+		Drawdown: template.HTML(drawdownChart(in.Result.Equity, in.Result.InitialCash)),
+	}
+	if p.Title == "" {
+		p.Title = in.Result.Strategy
+	}
+	if in.Engine != nil {
+		scores := make([]float64, len(in.Candles))
+		for i := range scores {
+			score, _ := in.Engine.Score(i)
+			scores[i] = float64(score)
+		}
+		p.Score = template.HTML(scoreChart(in.Candles, scores))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := pageTemplate.Execute(file, p); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+var pageTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"pct": func(v float64) float64 { return v * 100 },
+}).Parse(`<!DOCTYPE html>
+<html lang="zh">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "PingFang SC", "Microsoft YaHei", sans-serif; margin: 24px; color: #222; }
+h1 { font-size: 20px; }
+h2 { font-size: 16px; margin-top: 28px; border-bottom: 1px solid #ddd; padding-bottom: 4px; }
+table { border-collapse: collapse; font-size: 12px; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: right; }
+th { background: #f5f5f5; }
+td.l { text-align: left; }
+.meta { color: #666; font-size: 12px; }
+.pos { color: #2e7d32; } .neg { color: #c62828; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">策略: {{.Result.Strategy}} ｜ 交易方向: {{.Result.Mode}} ｜ 成交模型: {{.Result.Execution}} ｜ 成本模型: {{.Result.CostModel}} ｜ 仓位管理: {{.Result.Sizer}}</p>
+
+<h2>价格 / 指标叠加 / 买卖点</h2>
+{{.Price}}
+{{if .Score}}
+<h2>ScoringEngine 评分</h2>
+{{.Score}}
+{{end}}
+<h2>权益曲线</h2>
+{{.Equity}}
+
+<h2>回撤</h2>
+{{.Drawdown}}
+
+<h2>绩效指标</h2>
+<table>
+{{range .Metrics}}<tr><th class="l">{{.Name}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+
+<h2>交易明细</h2>
+<table>
+<tr><th>方向</th><th>开仓日期</th><th>开仓价</th><th>平仓日期</th><th>平仓价</th><th>数量</th><th>毛盈亏</th><th>费用</th><th>净盈亏</th><th>收益率</th><th class="l">离场原因</th></tr>
+{{range .Result.Trades}}<tr>
+<td class="l">{{.Direction}}</td><td>{{.EntryDate}}</td><td>{{printf "%.4f" .EntryPrice}}</td><td>{{.ExitDate}}</td><td>{{printf "%.4f" .ExitPrice}}</td>
+<td>{{printf "%.0f" .Qty}}</td><td>{{printf "%.2f" .GrossPnL}}</td><td>{{printf "%.2f" .Costs.Total}}</td>
+<td class="{{if gt .PnL 0.0}}pos{{else}}neg{{end}}">{{printf "%.2f" .PnL}}</td><td>{{printf "%.2f%%" (pct .ReturnPct)}}</td><td class="l">{{.ExitReason}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
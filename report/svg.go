@@ -0,0 +1,131 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// frame 一个 SVG 图表的坐标系：x 轴为 bar 下标，y 轴为数值
+type frame struct {
+	width, height          float64
+	padL, padR, padT, padB float64
+	n                      int
+	min, max               float64
+}
+
+func newFrame(width, height float64, n int, min, max float64) frame {
+	if max <= min {
+		max = min + 1
+	}
+	// 上下各留 5% 空白
+	span := max - min
+	return frame{
+		width: width, height: height,
+		padL: 64, padR: 16, padT: 12, padB: 28,
+		n: n, min: min - span*0.05, max: max + span*0.05,
+	}
+}
+
+func (f frame) plotW() float64 { return f.width - f.padL - f.padR }
+func (f frame) plotH() float64 { return f.height - f.padT - f.padB }
+
+// step 每根 bar 占的像素宽度
+func (f frame) step() float64 {
+	if f.n <= 0 {
+		return f.plotW()
+	}
+	return f.plotW() / float64(f.n)
+}
+
+// x bar 中心的横坐标
+func (f frame) x(i int) float64 {
+	return f.padL + (float64(i)+0.5)*f.step()
+}
+
+func (f frame) y(v float64) float64 {
+	return f.padT + (f.max-v)/(f.max-f.min)*f.plotH()
+}
+
+// open 输出 <svg> 开头与坐标轴、网格、日期刻度
+func (f frame) open(b *strings.Builder, dates []string, format string) {
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %.0f %.0f" width="100%%">`, f.width, f.height)
+	fmt.Fprintf(b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="#fff" stroke="#ccc"/>`, f.padL, f.padT, f.plotW(), f.plotH())
+
+	const gridLines = 5
+	for k := 0; k <= gridLines; k++ {
+		v := f.min + (f.max-f.min)*float64(k)/gridLines
+		y := f.y(v)
+		fmt.Fprintf(b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#eee"/>`, f.padL, y, f.width-f.padR, y)
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" font-size="10" text-anchor="end" fill="#666">%s</text>`, f.padL-4, y+3, fmt.Sprintf(format, v))
+	}
+
+	const dateTicks = 8
+	if f.n > 0 && len(dates) == f.n {
+		every := max(1, f.n/dateTicks)
+		for i := 0; i < f.n; i += every {
+			fmt.Fprintf(b, `<text x="%.1f" y="%.1f" font-size="10" text-anchor="middle" fill="#666">%s</text>`,
+				f.x(i), f.height-f.padB+16, escape(dates[i]))
+		}
+	}
+}
+
+func (f frame) close(b *strings.Builder) {
+	b.WriteString(`</svg>`)
+}
+
+// polyline 折线，跳过无效值（NaN 或指标预热期的 0）
+func (f frame) polyline(b *strings.Builder, series []float64, color string, width float64, dash string) {
+	var pts []string
+	flush := func() {
+		if len(pts) > 1 {
+			fmt.Fprintf(b, `<polyline fill="none" stroke="%s" stroke-width="%.1f"`, color, width)
+			if dash != "" {
+				fmt.Fprintf(b, ` stroke-dasharray="%s"`, dash)
+			}
+			fmt.Fprintf(b, ` points="%s"/>`, strings.Join(pts, " "))
+		}
+		pts = pts[:0]
+	}
+	for i, v := range series {
+		if !valid(v) {
+			flush()
+			continue
+		}
+		pts = append(pts, fmt.Sprintf("%.1f,%.1f", f.x(i), f.y(v)))
+	}
+	flush()
+}
+
+// hline 水平参考线
+func (f frame) hline(b *strings.Builder, v float64, color string) {
+	y := f.y(v)
+	fmt.Fprintf(b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-dasharray="4 3"/>`, f.padL, y, f.width-f.padR, y, color)
+}
+
+func valid(v float64) bool {
+	return v != 0 && !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// bounds 多个序列的最小/最大有效值
+func bounds(series ...[]float64) (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		for _, v := range s {
+			if !valid(v) {
+				continue
+			}
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+	}
+	if math.IsInf(lo, 0) {
+		return 0, 1
+	}
+	return lo, hi
+}
+
+func escape(s string) string {
+	r := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+	return r.Replace(s)
+}
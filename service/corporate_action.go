@@ -0,0 +1,120 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+)
+
+type CorporateActionType string
+
+const (
+	ActionSplit         CorporateActionType = "split"         // 拆股：Ratio = 拆后股数 / 拆前股数
+	ActionConsolidation CorporateActionType = "consolidation" // 合股：Ratio = 合后股数 / 合前股数（< 1）
+	ActionBonus         CorporateActionType = "bonus"         // 红股：Ratio = 每 1 股获送新股数
+	ActionRights        CorporateActionType = "rights"        // 附加股：Ratio = 每 1 股可认购新股数，Price 为认购价
+	ActionDividend      CorporateActionType = "dividend"      // 现金股息：Amount 为每股股息
+)
+
+// CorporateAction 一次公司行动，ExDate 为除权/除息日（YYYY-MM-DD）
+type CorporateAction struct {
+	ExDate string
+	Type   CorporateActionType
+	Ratio  float64
+	Amount float64
+	Price  float64
+}
+
+type AdjustOptions struct {
+	// TotalReturn 同时按股息复权，相当于股息再投入的总回报序列
+	TotalReturn bool
+}
+
+/*
+AdjustCandles 前复权：以最新价格为基准，按每次公司行动的价格因子调整除权日之前的 K 线
+拆股 / 合股 / 红股：价格 ÷ 股数倍数，成交量 × 股数倍数
+附加股：价格 × 理论除权价(TERP) / 除权前收盘价，成交量不变
+股息：仅 TotalReturn 时调整，价格 × (除息前收盘价 - 股息) / 除息前收盘价
+除权日早于首根或晚于末根 K 线的行动不影响数据
+返回新的切片，原数据不变
+*/
+func AdjustCandles(candles []Candle, actions []CorporateAction, opt AdjustOptions) ([]Candle, error) {
+	out := make([]Candle, len(candles))
+	copy(out, candles)
+	if len(candles) == 0 || len(actions) == 0 {
+		return out, nil
+	}
+
+	priceFactor := make([]float64, len(candles)+1)
+	volumeFactor := make([]float64, len(candles)+1)
+	for i := range priceFactor {
+		priceFactor[i] = 1
+		volumeFactor[i] = 1
+	}
+
+	for _, a := range actions {
+		// 除权日当根及之后为除权后价格
+		k := sort.Search(len(candles), func(i int) bool { return candles[i].Date >= a.ExDate })
+		if k == 0 || k == len(candles) {
+			continue
+		}
+		cum := candles[k-1].Close
+
+		price, volume := 1.0, 1.0
+		switch a.Type {
+		case ActionSplit, ActionConsolidation:
+			if a.Ratio <= 0 {
+				return nil, fmt.Errorf("%s on %s: ratio must be positive", a.Type, a.ExDate)
+			}
+			price, volume = 1/a.Ratio, a.Ratio
+		case ActionBonus:
+			if a.Ratio <= 0 {
+				return nil, fmt.Errorf("%s on %s: ratio must be positive", a.Type, a.ExDate)
+			}
+			price, volume = 1/(1+a.Ratio), 1+a.Ratio
+		case ActionRights:
+			if a.Ratio <= 0 || a.Price < 0 {
+				return nil, fmt.Errorf("%s on %s: ratio must be positive and price non-negative", a.Type, a.ExDate)
+			}
+			terp := (cum + a.Ratio*a.Price) / (1 + a.Ratio)
+			// 认购价高于市价时附加股没有价值，不调整
+			if terp < cum {
+				price = terp / cum
+			}
+		case ActionDividend:
+			if !opt.TotalReturn {
+				continue
+			}
+			if a.Amount <= 0 || a.Amount >= cum {
+				return nil, fmt.Errorf("%s on %s: amount %.4f invalid for close %.4f", a.Type, a.ExDate, a.Amount, cum)
+			}
+			price = (cum - a.Amount) / cum
+		default:
+			return nil, fmt.Errorf("unknown corporate action type %q on %s", a.Type, a.ExDate)
+		}
+
+		priceFactor[k] *= price
+		volumeFactor[k] *= volume
+	}
+
+	// 从后往前累乘：第 i 根受 i 之后所有除权日的影响
+	pf, vf := 1.0, 1.0
+	for i := len(out) - 1; i >= 0; i-- {
+		pf *= priceFactor[i+1]
+		vf *= volumeFactor[i+1]
+		if pf == 1 && vf == 1 {
+			continue
+		}
+
+		c := &out[i]
+		// 数据源自带的复权价已经调整过，不再重复调整
+		if c.AdjClose == c.Close {
+			c.AdjClose *= pf
+		}
+		c.Open *= pf
+		c.High *= pf
+		c.Low *= pf
+		c.Close *= pf
+		c.Volume *= vf
+	}
+	return out, nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	evaluate2 "wolf_street/evaluate"
+)
+
+// Params Evaluator 参数，按名称取值，缺省时使用默认值
+type Params map[string]float64
+
+func (p Params) Float(key string, def float64) float64 {
+	if v, ok := p[key]; ok {
+		return v
+	}
+	return def
+}
+
+func (p Params) Int(key string, def int) int {
+	if v, ok := p[key]; ok {
+		return int(v)
+	}
+	return def
+}
+
+// Bool 非 0 即为 true
+func (p Params) Bool(key string, def bool) bool {
+	if v, ok := p[key]; ok {
+		return v != 0
+	}
+	return def
+}
+
+// paramCheck 依次读取参数并记录第一个非法值与已读取的参数名，factory 读完全部参数后调用 done
+type paramCheck struct {
+	p    Params
+	used map[string]bool
+	err  error
+}
+
+func checkParams(p Params) *paramCheck {
+	return &paramCheck{p: p, used: map[string]bool{}}
+}
+
+func (c *paramCheck) fail(format string, args ...any) {
+	if c.err == nil {
+		c.err = fmt.Errorf(format, args...)
+	}
+}
+
+func (c *paramCheck) float(key string, def float64) float64 {
+	c.used[key] = true
+	return c.p.Float(key, def)
+}
+
+func (c *paramCheck) boolean(key string, def bool) bool {
+	c.used[key] = true
+	return c.p.Bool(key, def)
+}
+
+// period 周期类参数，必须为正整数
+func (c *paramCheck) period(key string, def int) int {
+	v := c.float(key, float64(def))
+	if v < 1 || v != math.Trunc(v) {
+		c.fail("param %s must be a positive integer, got %v", key, v)
+		return def
+	}
+	return int(v)
+}
+
+// positive 必须大于 0 的参数
+func (c *paramCheck) positive(key string, def float64) float64 {
+	v := c.float(key, def)
+	if !(v > 0) {
+		c.fail("param %s must be > 0, got %v", key, v)
+		return def
+	}
+	return v
+}
+
+// done 返回第一个非法值；没有时检查是否有 factory 未读取的参数（多为拼写错误）
+func (c *paramCheck) done() error {
+	if c.err != nil {
+		return c.err
+	}
+	var unknown []string
+	for key := range c.p {
+		if !c.used[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown params %v", unknown)
+	}
+	return nil
+}
+
+// EvaluatorFactory 由共享指标集与参数构造 Evaluator
+type EvaluatorFactory func(data *IndicatorSet, p Params) (evaluate2.Evaluator, error)
+
+var evaluatorRegistry = map[string]EvaluatorFactory{}
+
+// RegisterEvaluator 按名称注册 Evaluator，重名时 panic
+func RegisterEvaluator(name string, factory EvaluatorFactory) {
+	if _, ok := evaluatorRegistry[name]; ok {
+		panic("evaluator already registered: " + name)
+	}
+	evaluatorRegistry[name] = factory
+}
+
+// RegisteredEvaluators 已注册的 Evaluator 名称（按字母序）
+func RegisteredEvaluators() []string {
+	names := make([]string, 0, len(evaluatorRegistry))
+	for name := range evaluatorRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewEvaluator 按名称构造已注册的 Evaluator
+func NewEvaluator(name string, data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	factory, ok := evaluatorRegistry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown evaluator %q", name)
+	}
+	ev, err := factory(data, p)
+	if err != nil {
+		return nil, fmt.Errorf("evaluator %s: %w", name, err)
+	}
+	return ev, nil
+}
+
+// weightedEvaluator 按权重缩放 Evaluator 的得分
+type weightedEvaluator struct {
+	evaluate2.Evaluator
+	weight float64
+}
+
+func (w weightedEvaluator) Evaluate(index int) (evaluate2.EvalResult, error) {
+	res, err := w.Evaluator.Evaluate(index)
+	if err != nil {
+		return res, err
+	}
+	res.Score *= w.weight
+	for k, v := range res.Components {
+		res.Components[k] = v * w.weight
+	}
+	for k, v := range res.SignalScores {
+		res.SignalScores[k] = v * w.weight
+	}
+	return res, nil
+}
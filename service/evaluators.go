@@ -0,0 +1,454 @@
+package service
+
+import (
+	evaluate2 "wolf_street/evaluate"
+)
+
+/*
+内置 Evaluator，对应原 ScoringEngine.Score 中的各段规则
+振荡指标（rsi / stoch_rsi / cci / kdj / williams_r）与 ichimoku 包装 evaluate 包的评分函数，
+其余简单规则用 ruleEvaluator 实现；参数名见各 factory 的 Params 取值
+*/
+func init() {
+	RegisterEvaluator("rsi", newRSIEvaluator)
+	RegisterEvaluator("stoch_rsi", newStochRSIEvaluator)
+	RegisterEvaluator("cci", newCCIEvaluator)
+	RegisterEvaluator("kdj", newKDJEvaluator)
+	RegisterEvaluator("williams_r", newWilliamsREvaluator)
+	RegisterEvaluator("bollinger", newBollingerEvaluator)
+	RegisterEvaluator("ema", newEMAEvaluator)
+	RegisterEvaluator("macd", newMACDEvaluator)
+	RegisterEvaluator("sar", newSAREvaluator)
+	RegisterEvaluator("atr", newATREvaluator)
+	RegisterEvaluator("vwap", newVWAPEvaluator)
+	RegisterEvaluator("arbr", newARBREvaluator)
+	RegisterEvaluator("cr", newCREvaluator)
+	RegisterEvaluator("ichimoku", newIchimokuEvaluator)
+	RegisterEvaluator("keltner", newKeltnerEvaluator)
+	RegisterEvaluator("td_sequential", newTDSequentialEvaluator)
+	RegisterEvaluator("volume", newVolumeEvaluator)
+	RegisterEvaluator("mtf", newMTFEvaluator)
+}
+
+// ruleEvaluator 以闭包实现的简单规则，rule 通过 res.Hit 记录信号
+type ruleEvaluator struct {
+	name   string
+	warmup int
+	rule   func(index int, res *evaluate2.EvalResult)
+}
+
+func (e ruleEvaluator) Name() string { return e.name }
+func (e ruleEvaluator) Warmup() int  { return e.warmup }
+func (e ruleEvaluator) Evaluate(index int) (evaluate2.EvalResult, error) {
+	res := evaluate2.NewEvalResult()
+	e.rule(index, &res)
+	return res, nil
+}
+
+// percentileParams 参数 percentile=1 时启用近 percentile_window 根的分位数阈值
+func percentileParams(c *paramCheck, pb *evaluate2.PercentileBands) {
+	pb.Enabled = c.boolean("percentile", pb.Enabled)
+	pb.Window = c.period("percentile_window", pb.Window)
+}
+
+// ---- 振荡指标 ----
+
+func newRSIEvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	c := checkParams(p)
+	period := c.period("period", 14)
+	cfg := evaluate2.DefaultRSIConfig()
+	percentileParams(c, &cfg.Percentile)
+	if err := c.done(); err != nil {
+		return nil, err
+	}
+	return evaluate2.RSIEvaluator{RSI: data.RSI(period), Config: cfg, WarmupBars: RSIWarmup(period)}, nil
+}
+
+// stochRSIConfig ScoringEngine 使用的 StochRSI 评估参数
+func stochRSIConfig() evaluate2.StochRSIConfig {
+	cfg := evaluate2.DefaultStochRSIConfig()
+	// 可按需微调
+	cfg.SlopeLookback = 4
+	cfg.MinRiseBars = 3
+	cfg.CrossoverHysteresis = 2
+	cfg.EnableCrossBoost = true
+	cfg.EnableMTF = true
+	return cfg
+}
+
+func newStochRSIEvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	c := checkParams(p)
+	period, smoothK, smoothD := c.period("period", 14), c.period("smooth_k", 3), c.period("smooth_d", 3)
+	cfg := stochRSIConfig()
+	cfg.UsePercentile = c.boolean("percentile", cfg.UsePercentile)
+	cfg.PercentileWindow = c.period("percentile_window", cfg.PercentileWindow)
+	cfg.EnableMTF = c.boolean("mtf", cfg.EnableMTF)
+	if err := c.done(); err != nil {
+		return nil, err
+	}
+
+	kd := data.StochRSIKD(period, smoothK, smoothD)
+
+	eng := evaluate2.Engine{StochRSI: data.StochRSI(period), StochK: kd.K, StochD: kd.D}
+	if cfg.EnableMTF {
+		eng.StochRSIHTF = data.HTF(Weekly).StochRSI
+	}
+	return evaluate2.StochRSIEvaluator{Engine: eng, Config: cfg, WarmupBars: StochRSIKDWarmup(period, smoothK, smoothD)}, nil
+}
+
+func newCCIEvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	c := checkParams(p)
+	period := c.period("period", 20)
+	cfg := evaluate2.DefaultCCIConfig()
+	percentileParams(c, &cfg.Percentile)
+	if err := c.done(); err != nil {
+		return nil, err
+	}
+	return evaluate2.CCIEvaluator{CCI: data.CCI(period), Config: cfg, WarmupBars: CCIWarmup(period)}, nil
+}
+
+func newKDJEvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	c := checkParams(p)
+	period := c.period("period", 9)
+	cfg := evaluate2.DefaultKDJConfig()
+	percentileParams(c, &cfg.Percentile)
+	if err := c.done(); err != nil {
+		return nil, err
+	}
+	return evaluate2.KDJEvaluator{
+		KDJ:        kdjAdapter{ref: data.KDJ(period)},
+		Prices:     priceAdapter{ref: data.Closes},
+		Config:     cfg,
+		WarmupBars: KDJWarmup(period),
+	}, nil
+}
+
+func newWilliamsREvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	c := checkParams(p)
+	period := c.period("period", 14)
+	cfg := evaluate2.DefaultWilliamsRConfig()
+	percentileParams(c, &cfg.Percentile)
+	if err := c.done(); err != nil {
+		return nil, err
+	}
+	return evaluate2.WilliamsREvaluator{WilliamsR: data.WilliamsR(period), Config: cfg, WarmupBars: WilliamsRWarmup(period)}, nil
+}
+
+// ---- 趋势 / 通道类规则 ----
+
+func newBollingerEvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	c := checkParams(p)
+	period := c.period("period", 20)
+	if err := c.done(); err != nil {
+		return nil, err
+	}
+	bb := data.Bollinger(period)
+	prices := data.Closes
+	return ruleEvaluator{name: "bollinger", warmup: BollingerWarmup(period), rule: func(i int, res *evaluate2.EvalResult) {
+		if prices[i] < bb.LowerBand[i] {
+			res.Hit("bollinger_lower", "布林带下轨突破", 1)
+		} else if prices[i] > bb.UpperBand[i] {
+			res.Hit("bollinger_upper", "布林带上轨突破", -1)
+		}
+	}}, nil
+}
+
+func newEMAEvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	c := checkParams(p)
+	shortPeriod, longPeriod := c.period("short", 12), c.period("long", 26)
+	if err := c.done(); err != nil {
+		return nil, err
+	}
+	short, long := data.EMA(shortPeriod), data.EMA(longPeriod)
+	return ruleEvaluator{name: "ema", warmup: max(EMAWarmup(shortPeriod), EMAWarmup(longPeriod)), rule: func(i int, res *evaluate2.EvalResult) {
+		if short[i] > long[i] {
+			res.Hit("ema_golden", "EMA金叉", 1)
+		} else if short[i] < long[i] {
+			res.Hit("ema_death", "EMA死叉", -1)
+		}
+	}}, nil
+}
+
+// macdCross 第 index 根 MACD 线与信号线的交叉：1 金叉，-1 死叉，0 无
+func macdCross(m MACD, index int) int {
+	if index < 1 {
+		return 0
+	}
+	if m.MACDLine[index-1] < m.SignalLine[index-1] && m.MACDLine[index] > m.SignalLine[index] {
+		return 1
+	}
+	if m.MACDLine[index-1] > m.SignalLine[index-1] && m.MACDLine[index] < m.SignalLine[index] {
+		return -1
+	}
+	return 0
+}
+
+func newMACDEvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	if err := checkParams(p).done(); err != nil {
+		return nil, err
+	}
+	macd := data.MACD()
+	return ruleEvaluator{name: "macd", warmup: MACDWarmup() + 1, rule: func(i int, res *evaluate2.EvalResult) {
+		switch macdCross(macd, i) {
+		case 1:
+			res.Hit("macd_golden", "MACD金叉", 1)
+		case -1:
+			res.Hit("macd_death", "MACD死叉", -1)
+		}
+	}}, nil
+}
+
+func newSAREvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	c := checkParams(p)
+	af, maxAF := c.positive("af", 0.02), c.positive("max_af", 0.2)
+	if c.err == nil && maxAF < af {
+		c.fail("param max_af (%v) must be >= af (%v)", maxAF, af)
+	}
+	if err := c.done(); err != nil {
+		return nil, err
+	}
+	sar := data.SAR(af, maxAF)
+	prices := data.Closes
+	return ruleEvaluator{name: "sar", warmup: SARWarmup(), rule: func(i int, res *evaluate2.EvalResult) {
+		if prices[i] > sar[i] {
+			res.Hit("sar_support", "SAR支撑", 1)
+		} else if prices[i] < sar[i] {
+			res.Hit("sar_resist", "SAR压制", -1)
+		}
+	}}, nil
+}
+
+// newATREvaluator ATR 只做辅助提示，不计分
+func newATREvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	c := checkParams(p)
+	period := c.period("period", 14)
+	if err := c.done(); err != nil {
+		return nil, err
+	}
+	atr := data.ATR(period)
+	return ruleEvaluator{name: "atr", warmup: ATRWarmup(period) + 1, rule: func(i int, res *evaluate2.EvalResult) {
+		if atr[i] > atr[i-1] {
+			res.Hit("atr_up", "ATR上升", 0)
+		} else if atr[i] < atr[i-1] {
+			res.Hit("atr_down", "ATR下降", 0)
+		}
+	}}, nil
+}
+
+func newVWAPEvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	if err := checkParams(p).done(); err != nil {
+		return nil, err
+	}
+	vwap := data.VWAP()
+	prices := data.Closes
+	return ruleEvaluator{name: "vwap", rule: func(i int, res *evaluate2.EvalResult) {
+		if prices[i] > vwap[i] {
+			res.Hit("vwap_above", "价格上穿VWAP（强势）", 1)
+		} else if prices[i] < vwap[i] {
+			res.Hit("vwap_below", "价格下穿VWAP（弱势）", -1)
+		}
+	}}, nil
+}
+
+func newARBREvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	c := checkParams(p)
+	strong, weak := c.float("strong", 120), c.float("weak", 80)
+	if c.err == nil && weak > strong {
+		c.fail("param weak (%v) must be <= strong (%v)", weak, strong)
+	}
+	if err := c.done(); err != nil {
+		return nil, err
+	}
+	arbr := data.ARBR()
+	return ruleEvaluator{name: "arbr", warmup: ARBRWarmup(), rule: func(i int, res *evaluate2.EvalResult) {
+		if arbr.AR[i] > strong && arbr.BR[i] > strong {
+			res.Hit("arbr_strong", "ARBR极强多头", 1)
+		} else if arbr.AR[i] < weak && arbr.BR[i] < weak {
+			res.Hit("arbr_weak", "ARBR极弱空头", -1)
+		}
+	}}, nil
+}
+
+func newCREvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	c := checkParams(p)
+	period := c.period("period", 26)
+	strong, weak := c.float("strong", 150), c.float("weak", 100)
+	if c.err == nil && weak > strong {
+		c.fail("param weak (%v) must be <= strong (%v)", weak, strong)
+	}
+	if err := c.done(); err != nil {
+		return nil, err
+	}
+	cr := data.CR(period)
+	return ruleEvaluator{name: "cr", warmup: CRWarmup(period), rule: func(i int, res *evaluate2.EvalResult) {
+		if cr[i] > strong {
+			res.Hit("cr_strong", "CR强多头确认", 1)
+		} else if cr[i] < weak {
+			res.Hit("cr_weak", "CR偏空头确认", -1)
+		}
+	}}, nil
+}
+
+func newIchimokuEvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	c := checkParams(p)
+	tenkan, kijun := c.period("tenkan", 9), c.period("kijun", 26)
+	senkouB, displacement := c.period("senkou_b", 52), c.period("displacement", 26)
+	if err := c.done(); err != nil {
+		return nil, err
+	}
+	ich := data.Ichimoku(tenkan, kijun, senkouB, displacement)
+	return evaluate2.IchimokuEvaluator{
+		Lines: evaluate2.IchimokuLines{
+			Tenkan:       ich.Tenkan,
+			Kijun:        ich.Kijun,
+			SenkouA:      ich.SenkouA,
+			SenkouB:      ich.SenkouB,
+			Displacement: ich.Displacement,
+		},
+		Closes:     data.Closes,
+		Config:     evaluate2.DefaultIchimokuConfig(),
+		WarmupBars: max(IchimokuWarmup(tenkan), IchimokuWarmup(kijun)),
+	}, nil
+}
+
+func newKeltnerEvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	c := checkParams(p)
+	period := c.period("period", 20)
+	if err := c.done(); err != nil {
+		return nil, err
+	}
+	kc := data.KC(period)
+	prices := data.Closes
+	return ruleEvaluator{name: "keltner", warmup: KCWarmup(period), rule: func(i int, res *evaluate2.EvalResult) {
+		if prices[i] > kc.UpperBand[i] {
+			res.Hit("keltner_upper", "价格突破Keltner上轨（趋势强势）", 1)
+		} else if prices[i] < kc.LowerBand[i] {
+			res.Hit("keltner_lower", "价格跌破Keltner下轨（弱势）", -1)
+		}
+	}}, nil
+}
+
+func newTDSequentialEvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	if err := checkParams(p).done(); err != nil {
+		return nil, err
+	}
+	td := data.TDSequential()
+	return ruleEvaluator{name: "td_sequential", warmup: TDSequentialWarmup(), rule: func(i int, res *evaluate2.EvalResult) {
+		if td[i] == 9 {
+			res.Hit("td9_top", "TD9顶部反转警告", -1)
+		} else if td[i] == -9 {
+			res.Hit("td9_bottom", "TD9底部反转警告", 1)
+		}
+	}}, nil
+}
+
+// ---- 成交量 / 多周期 ----
+
+// newVolumeEvaluator 成交量类信号，没有成交量数据的 bar 全部跳过
+func newVolumeEvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	c := checkParams(p)
+	obvLookback := c.period("obv_lookback", 5)
+	period, mfiPeriod := c.period("period", 20), c.period("mfi_period", 14)
+	spike := c.positive("spike", 2)
+	if err := c.done(); err != nil {
+		return nil, err
+	}
+	obv := data.OBV()
+	mfi := data.MFI(mfiPeriod)
+	cmf := data.CMF(period)
+	volumeSMA := data.VolumeSMA(period)
+	volumeSpike := data.VolumeSpike(period)
+	candles, prices := data.Candles, data.Closes
+
+	return ruleEvaluator{name: "volume", warmup: max(obvLookback, MFIWarmup(mfiPeriod), VolumeSpikeWarmup(period)), rule: func(i int, res *evaluate2.EvalResult) {
+		candle := candles[i]
+		if candle.Volume <= 0 {
+			return
+		}
+
+		// OBV 与价格同向确认
+		if i >= obvLookback {
+			obvChange := volumeTrend(obv, i, obvLookback)
+			priceChange := prices[i] - prices[i-obvLookback]
+			if obvChange > 0 && priceChange > 0 {
+				res.Hit("obv_up", "OBV量价齐升", 1)
+			} else if obvChange < 0 && priceChange < 0 {
+				res.Hit("obv_down", "OBV量价齐跌", -1)
+			}
+		}
+
+		// MFI
+		if mfi[i] < 20 {
+			res.Hit("mfi_os", "MFI超卖", 1)
+		} else if mfi[i] > 80 {
+			res.Hit("mfi_ob", "MFI超买", -1)
+		}
+
+		// Chaikin Money Flow
+		if cmf[i] > 0.1 {
+			res.Hit("cmf_in", "CMF资金流入", 1)
+		} else if cmf[i] < -0.1 {
+			res.Hit("cmf_out", "CMF资金流出", -1)
+		}
+
+		// 成交量高于均量时的涨跌方向
+		if volumeSMA[i] > 0 && candle.Volume > volumeSMA[i] {
+			if prices[i] > prices[i-1] {
+				res.Hit("volume_up", "量增价涨（高于均量）", 1)
+			} else if prices[i] < prices[i-1] {
+				res.Hit("volume_down", "量增价跌（高于均量）", -1)
+			}
+		}
+
+		// 放量（默认 ≥2 倍）阳线 / 阴线
+		if volumeSpike[i] >= spike {
+			if candle.Close > candle.Open {
+				res.Hit("spike_bull", "放量阳线", 1)
+			} else if candle.Close < candle.Open {
+				res.Hit("spike_bear", "放量阴线", -1)
+			}
+		}
+	}}, nil
+}
+
+// newMTFEvaluator 日线与周线同向时加减分（StochRSI 共振在 stoch_rsi 中处理）
+// 参数：rsi_period、rsi_oversold / rsi_overbought（默认 30 / 70）、w_rsi_os / w_rsi_ob、w_macd_golden / w_macd_death（默认 ±1）
+func newMTFEvaluator(data *IndicatorSet, p Params) (evaluate2.Evaluator, error) {
+	c := checkParams(p)
+	rsiPeriod := c.period("rsi_period", 14)
+	// 共振阈值与权重，空头权重为负数
+	oversold, overbought := c.float("rsi_oversold", 30), c.float("rsi_overbought", 70)
+	wRSIOS, wRSIOB := c.float("w_rsi_os", 1), c.float("w_rsi_ob", -1)
+	wMACDGolden, wMACDDeath := c.float("w_macd_golden", 1), c.float("w_macd_death", -1)
+	if c.err == nil && !(0 <= oversold && oversold < overbought && overbought <= 100) {
+		c.fail("params rsi_oversold (%v) / rsi_overbought (%v) must satisfy 0 <= oversold < overbought <= 100", oversold, overbought)
+	}
+	if err := c.done(); err != nil {
+		return nil, err
+	}
+	rsi := data.RSI(rsiPeriod)
+	macd := data.MACD()
+	htf := data.HTF(Weekly)
+
+	return ruleEvaluator{name: "mtf", warmup: RSIWarmup(rsiPeriod), rule: func(i int, res *evaluate2.EvalResult) {
+		// RSI 日线与高周期同时超卖 / 超买
+		if rsi[i] < oversold && htf.RSI[i] < oversold {
+			res.Hit("mtf_rsi_os", "RSI多周期共振超卖", wRSIOS)
+		} else if rsi[i] > overbought && htf.RSI[i] > overbought {
+			res.Hit("mtf_rsi_ob", "RSI多周期共振超买", wRSIOB)
+		}
+
+		// 日线 MACD 金叉 / 死叉，且高周期 MACD 在信号线同侧
+		htfLine, htfSignal := htf.MACD.MACDLine[i], htf.MACD.SignalLine[i]
+		switch macdCross(macd, i) {
+		case 1:
+			if htfLine > htfSignal {
+				res.Hit("mtf_macd_golden", "MACD金叉（高周期多头共振）", wMACDGolden)
+			}
+		case -1:
+			if htfLine < htfSignal {
+				res.Hit("mtf_macd_death", "MACD死叉（高周期空头共振）", wMACDDeath)
+			}
+		}
+	}}, nil
+}
@@ -1,13 +1,8 @@
 package service
 
-import (
-	"math"
-	"time"
-)
+import "math"
 
 func CalculateKeltnerChannel(highs, lows, closes []float64, period int) KC {
-	bar := NewTaggedProgressBar(len(highs), period)
-
 	n := len(closes)
 	upperBand := make([]float64, n)
 	middleBand := CalculateEMA(closes, period)
@@ -17,12 +12,8 @@ func CalculateKeltnerChannel(highs, lows, closes []float64, period int) KC {
 	for i := 0; i < n; i++ {
 		upperBand[i] = middleBand[i] + 2*atr[i]
 		lowerBand[i] = middleBand[i] - 2*atr[i]
-
-		bar.Add(1)
-		time.Sleep(6 * time.Millisecond)
 	}
 
-	bar.Finish()
 	return KC{
 		UpperBand:  upperBand,
 		MiddleBand: middleBand,
@@ -31,8 +22,6 @@ func CalculateKeltnerChannel(highs, lows, closes []float64, period int) KC {
 }
 
 func CalculateTDSequential(prices []float64) []int {
-	bar := NewTaggedProgressBar(len(prices), len(prices))
-
 	n := len(prices)
 	td := make([]int, n)
 	countUp := 0
@@ -57,37 +46,32 @@ func CalculateTDSequential(prices []float64) []int {
 			td[i] = -9
 			countDown = 0
 		}
-
-		bar.Add(1)
-		time.Sleep(6 * time.Millisecond)
 	}
 
-	bar.Finish()
 	return td
 }
 
 /*
 VWAP：价格上穿/下穿 VWAP 判定强弱势
+从第一根开始累计；滚动/锚定版本见 CalculateRollingVWAP / CalculateAnchoredVWAP
 */
 func CalculateVWAP(candles []Candle) []float64 {
-	bar := NewTaggedProgressBar(len(candles), len(candles))
-
 	n := len(candles)
 	vwap := make([]float64, n)
 	var cumulativePV, cumulativeVolume float64
+	withVolume := hasVolume(candles)
 
 	for i := 0; i < n; i++ {
-		price := (candles[i].High + candles[i].Low + candles[i].Close) / 3
-		volume := 1.0 // 若无成交量数据，假设为1
+		price := typicalPrice(candles[i])
+		volume := candles[i].Volume
+		if !withVolume {
+			volume = 1 // 若无成交量数据，假设为1
+		}
 		cumulativePV += price * volume
 		cumulativeVolume += volume
 		vwap[i] = cumulativePV / cumulativeVolume
-
-		bar.Add(1)
-		time.Sleep(6 * time.Millisecond)
 	}
 
-	bar.Finish()
 	return vwap
 }
 
@@ -96,11 +80,12 @@ ARBR > 120 判定为极强多头，
 ARBR < 80 判定为极弱空头
 */
 func CalculateARBR(candles []Candle) ARBR {
-	bar := NewTaggedProgressBar(len(candles), len(candles))
-
 	n := len(candles)
 	AR := make([]float64, n)
 	BR := make([]float64, n)
+	if n > 0 {
+		AR[0], BR[0] = math.NaN(), math.NaN()
+	}
 
 	for i := 1; i < n; i++ {
 		HO := candles[i].High - candles[i].Open
@@ -113,12 +98,8 @@ func CalculateARBR(candles []Candle) ARBR {
 		if LC != 0 {
 			BR[i] = HC / LC * 100
 		}
-
-		bar.Add(1)
-		time.Sleep(6 * time.Millisecond)
 	}
 
-	bar.Finish()
 	return ARBR{
 		AR: AR,
 		BR: BR,
@@ -130,58 +111,73 @@ CR：> 150 强多头确认
 CR：< 100 偏空头确认
 */
 func CalculateCR(candles []Candle, period int) []float64 {
-	bar := NewTaggedProgressBar(len(candles), period)
-
 	n := len(candles)
-	cr := make([]float64, n)
+	cr := nanSeries(n)
 
-	for i := period; i < n; i++ {
-		HMP := 0.0
-		LMP := 0.0
-		for j := i - period + 1; j <= i; j++ {
-			mp := (candles[j-1].High + candles[j-1].Low) / 2
-			HMP += math.Max(0, candles[j].High-mp)
-			LMP += math.Max(0, mp-candles[j].Low)
+	HMP, LMP := newRollingSum(period), newRollingSum(period)
+
+	for i := 1; i < n; i++ {
+		mp := (candles[i-1].High + candles[i-1].Low) / 2
+		HMP.push(math.Max(0, candles[i].High-mp))
+		LMP.push(math.Max(0, mp-candles[i].Low))
+		if i < period {
+			continue
 		}
-		if LMP != 0 {
-			cr[i] = HMP / LMP * 100
+		cr[i] = 0
+		if LMP.sum != 0 {
+			cr[i] = HMP.sum / LMP.sum * 100
 		}
-
-		bar.Add(1)
-		time.Sleep(6 * time.Millisecond)
 	}
 
-	bar.Finish()
 	return cr
 }
 
-// Ichimoku (基准线示例)
 /*
-Ichimoku：价格上穿/下穿基准线判定偏多/偏空
+Ichimoku 一目均衡表（常用参数 9 / 26 / 52 / 26）：
+转换线 = 近 tenkanPeriod 根 (最高+最低)/2，基准线 = 近 kijunPeriod 根 (最高+最低)/2
+先行带 A = (转换线+基准线)/2、先行带 B = 近 senkouBPeriod 根 (最高+最低)/2，均向前平移 displacement 根构成云层
+迟行线 = 收盘价向后平移 displacement 根（Chikou[i] 是未来收盘价，仅供画图，见 Ichimoku 结构体说明）
 */
-func CalculateIchimokuBaseLine(highs, lows []float64, period int) []float64 {
-	bar := NewTaggedProgressBar(len(highs), period)
+func CalculateIchimoku(highs, lows, closes []float64, tenkanPeriod, kijunPeriod, senkouBPeriod, displacement int) Ichimoku {
+	n := len(closes)
+	tenkan := CalculateIchimokuBaseLine(highs, lows, tenkanPeriod)
+	kijun := CalculateIchimokuBaseLine(highs, lows, kijunPeriod)
+	spanB := CalculateIchimokuBaseLine(highs, lows, senkouBPeriod)
 
+	senkouA, senkouB := nanSeries(n+displacement), nanSeries(n+displacement)
+	for i := 0; i < n; i++ {
+		senkouA[i+displacement] = (tenkan[i] + kijun[i]) / 2
+		senkouB[i+displacement] = spanB[i]
+	}
+
+	chikou := nanSeries(n)
+	for i := displacement; i < n; i++ {
+		chikou[i-displacement] = closes[i]
+	}
+
+	return Ichimoku{
+		Tenkan:       tenkan,
+		Kijun:        kijun,
+		SenkouA:      senkouA,
+		SenkouB:      senkouB,
+		Chikou:       chikou,
+		Displacement: displacement,
+	}
+}
+
+// CalculateIchimokuBaseLine 近 period 根 (最高+最低)/2，即基准线；转换线、先行带 B 同样按此计算
+func CalculateIchimokuBaseLine(highs, lows []float64, period int) []float64 {
 	n := len(highs)
-	baseLine := make([]float64, n)
-	for i := period - 1; i < n; i++ {
-		highest := highs[i-period+1]
-		lowest := lows[i-period+1]
-		for j := i - period + 1; j <= i; j++ {
-			if highs[j] > highest {
-				highest = highs[j]
-			}
-			if lows[j] < lowest {
-				lowest = lows[j]
-			}
+	baseLine := nanSeries(n)
+	highest, lowest := newRollingMax(period), newRollingMin(period)
+	for i := 0; i < n; i++ {
+		highest.push(highs[i])
+		lowest.push(lows[i])
+		if i >= period-1 {
+			baseLine[i] = (highest.value() + lowest.value()) / 2
 		}
-		baseLine[i] = (highest + lowest) / 2
-
-		bar.Add(1)
-		time.Sleep(6 * time.Millisecond)
 	}
 
-	bar.Finish()
 	return baseLine
 }
 
@@ -190,30 +186,27 @@ KDJ 信号 :
 高位 (>80) 警惕回落，低位 (<20) 关注反弹：
 超过 80：股价高位，注意风险。
 低于 20：股价低位，可能反弹。
+预热期与窗口内最高价等于最低价（RSV 无定义）时 K / D / J 为 NaN
 */
 func CalculateKDJ(highs, lows, closes []float64, period int) []KDJValue {
-	bar := NewTaggedProgressBar(len(closes), period)
-
 	n := len(closes)
 	kdj := make([]KDJValue, n)
+	undefined := KDJValue{K: math.NaN(), D: math.NaN(), J: math.NaN()}
+	for i := range kdj {
+		kdj[i] = undefined
+	}
 
 	var k, d float64 = 50, 50
+	highest, lowest := newRollingMax(period), newRollingMin(period)
 
-	for i := period - 1; i < n; i++ {
-		low := lows[i-period+1]
-		high := highs[i-period+1]
-		for j := i - period + 1; j <= i; j++ {
-			if lows[j] < low {
-				low = lows[j]
-			}
-			if highs[j] > high {
-				high = highs[j]
-			}
-
-			bar.Add(1)
-			time.Sleep(6 * time.Millisecond)
+	for i := 0; i < n; i++ {
+		highest.push(highs[i])
+		lowest.push(lows[i])
+		if i < period-1 {
+			continue
 		}
 
+		high, low := highest.value(), lowest.value()
 		if high != low {
 			rsv := (closes[i] - low) / (high - low) * 100
 			k = k*2/3 + rsv/3
@@ -223,7 +216,6 @@ func CalculateKDJ(highs, lows, closes []float64, period int) []KDJValue {
 		}
 	}
 
-	bar.Finish()
 	return kdj
 }
 
@@ -234,19 +226,23 @@ SAR 点在价格上方 = 下跌趋势（持有空单或卖出）。
 SAR 点“翻转”位置时 = 趋势可能反转：
 价格下方变上方：卖出信号。
 价格上方变下方：买入信号。
+起算：按前两根的方向动量（+DM / -DM，负值记 0）定初始趋势，-DM 更大时为下降趋势；
+上升趋势第 2 根 SAR 取第 1 根最低价、极值点取第 2 根最高价，下降趋势反之。第 1 根没有 SAR，为 NaN
 */
 func CalculateSAR(highs, lows []float64, accelerationFactor float64, maxAccelerationFactor float64) []float64 {
-	bar := NewTaggedProgressBar(len(highs), len(lows))
-
 	n := len(highs)
-	sar := make([]float64, n)
+	sar := nanSeries(n)
+	if n < 2 {
+		return sar
+	}
 
-	isUptrend := true
+	isUptrend, seed := sarSeed(highs[0], lows[0], highs[1], lows[1])
 	af := accelerationFactor
-	highest := highs[0]
-	lowest := lows[0]
+	highest := highs[1]
+	lowest := lows[1]
+	sar[1] = seed
 
-	for i := 1; i < n; i++ {
+	for i := 2; i < n; i++ {
 		if isUptrend {
 			sar[i] = sar[i-1] + af*(highest-sar[i-1])
 			if lows[i] < sar[i] {
@@ -274,23 +270,29 @@ func CalculateSAR(highs, lows []float64, accelerationFactor float64, maxAccelera
 				}
 			}
 		}
-
-		bar.Add(1)
-		time.Sleep(6 * time.Millisecond)
 	}
 
-	bar.Finish()
 	return sar
 }
 
+// sarSeed 由前两根 K 线确定 SAR 的初始趋势与第 2 根的 SAR
+func sarSeed(high0, low0, high1, low1 float64) (uptrend bool, sar float64) {
+	plusDM, minusDM := math.Max(high1-high0, 0), math.Max(low0-low1, 0)
+	if minusDM > plusDM {
+		return false, high0
+	}
+	return true, low0
+}
+
 /*
 RSI < 30 → 超卖区，考虑买入
 RSI > 70 → 超买区，考虑卖出
 */
 func CalculateRSI(prices []float64, period int) []float64 {
-	bar := NewTaggedProgressBar(len(prices), period)
-
-	rsi := make([]float64, len(prices))
+	rsi := nanSeries(len(prices))
+	if len(prices) <= period {
+		return rsi
+	}
 	var gainSum, lossSum float64
 
 	for i := 1; i <= period; i++ {
@@ -300,9 +302,6 @@ func CalculateRSI(prices []float64, period int) []float64 {
 		} else {
 			lossSum -= change
 		}
-
-		bar.Add(1)
-		time.Sleep(6 * time.Millisecond)
 	}
 
 	avgGain := gainSum / float64(period)
@@ -333,12 +332,6 @@ func CalculateRSI(prices []float64, period int) []float64 {
 		}
 	}
 
-	// period前的rsi值设为0或者NaN
-	for i := 0; i < period; i++ {
-		rsi[i] = 0
-	}
-
-	bar.Finish()
 	return rsi
 }
 
@@ -347,34 +340,24 @@ func CalculateRSI(prices []float64, period int) []float64 {
 价格上穿布林带上轨，考虑卖出
 */
 func CalculateBollinger(prices []float64, period int) BollingerBand {
-	bar := NewTaggedProgressBar(len(prices), period)
-
 	n := len(prices)
-	lowerBand := make([]float64, n)
-	upperBand := make([]float64, n)
+	lowerBand := nanSeries(n)
+	upperBand := nanSeries(n)
 	midBand := CalculateEMA(prices, period)
 
-	for i := period - 1; i < n; i++ {
-		sum := 0.0
-		for j := i - period + 1; j <= i; j++ {
-			sum += prices[j]
-		}
-		mean := sum / float64(period)
+	stats := newRollingStats(period)
 
-		variance := 0.0
-		for j := i - period + 1; j <= i; j++ {
-			variance += (prices[j] - mean) * (prices[j] - mean)
+	for i := 0; i < n; i++ {
+		stats.push(prices[i])
+		if !stats.full() {
+			continue
 		}
-		stddev := math.Sqrt(variance / float64(period))
+		stddev := math.Sqrt(stats.variance())
 
-		upperBand[i] = mean + 2*stddev
-		lowerBand[i] = mean - 2*stddev
-
-		bar.Add(1)
-		time.Sleep(6 * time.Millisecond)
+		upperBand[i] = stats.mean + 2*stddev
+		lowerBand[i] = stats.mean - 2*stddev
 	}
 
-	bar.Finish()
 	return BollingerBand{
 		LowerBand: lowerBand,
 		MidBand:   midBand,
@@ -385,28 +368,28 @@ func CalculateBollinger(prices []float64, period int) BollingerBand {
 /*
 短期EMA上穿长期EMA → 黄金交叉，买入信号
 短期EMA下穿长期EMA → 死亡交叉，卖出信号
+输入开头的 NaN（如上游指标的预热期）会被跳过，从第一个有效值起算
 */
 func CalculateEMA(prices []float64, period int) []float64 {
-	bar := NewTaggedProgressBar(len(prices), period)
-
-	ema := make([]float64, len(prices))
+	ema := nanSeries(len(prices))
 	k := 2.0 / (float64(period) + 1.0)
 
+	start := FirstValid(prices)
+	if len(prices)-start < period {
+		return ema
+	}
+
 	// 初始值用SMA
 	sum := 0.0
-	for i := 0; i < period; i++ {
+	for i := start; i < start+period; i++ {
 		sum += prices[i]
 	}
-	ema[period-1] = sum / float64(period)
+	ema[start+period-1] = sum / float64(period)
 
-	for i := period; i < len(prices); i++ {
+	for i := start + period; i < len(prices); i++ {
 		ema[i] = prices[i]*k + ema[i-1]*(1-k)
-
-		bar.Add(1)
-		time.Sleep(6 * time.Millisecond)
 	}
 
-	bar.Finish()
 	return ema
 }
 
@@ -415,30 +398,24 @@ MACD线上穿Signal线 → 金叉，买入信号
 MACD线下穿Signal线 → 死叉，卖出信号
 */
 func CalculateMACD(prices []float64) MACD {
-	bar := NewTaggedProgressBar(len(prices), len(prices))
-
 	n := len(prices)
 	macdLine := make([]float64, n)
-	signalLine := make([]float64, n)
 	histogram := make([]float64, n)
 
+	// EMA26 就绪前 MACD 线为 NaN，信号线从 MACD 线第一个有效值起算
 	ema12 := CalculateEMA(prices, 12)
 	ema26 := CalculateEMA(prices, 26)
 
 	for i := 0; i < n; i++ {
 		macdLine[i] = ema12[i] - ema26[i]
-
-		bar.Add(1)
-		time.Sleep(6 * time.Millisecond)
 	}
 
-	signalLine = CalculateEMA(macdLine, 9)
+	signalLine := CalculateEMA(macdLine, 9)
 
 	for i := 0; i < n; i++ {
 		histogram[i] = macdLine[i] - signalLine[i]
 	}
 
-	bar.Finish()
 	return MACD{
 		MACDLine:   macdLine,
 		SignalLine: signalLine,
@@ -451,10 +428,11 @@ ATR上升，信号可靠性增强 (不直接作为买卖信号)
 ATR下降，信号减弱 (辅助判断信号有效性)
 */
 func CalculateATR(highs, lows, closes []float64, period int) []float64 {
-	bar := NewTaggedProgressBar(len(closes), period)
-
-	atr := make([]float64, len(closes))
+	atr := nanSeries(len(closes))
 	trs := make([]float64, len(closes))
+	if len(closes) <= period {
+		return atr
+	}
 
 	for i := 1; i < len(closes); i++ {
 		highLow := highs[i] - lows[i]
@@ -462,43 +440,32 @@ func CalculateATR(highs, lows, closes []float64, period int) []float64 {
 		lowClose := math.Abs(lows[i] - closes[i-1])
 
 		trs[i] = math.Max(highLow, math.Max(highClose, lowClose))
-
-		bar.Add(1)
-		time.Sleep(6 * time.Millisecond)
 	}
 
 	// 初始ATR用SMA
 	sum := 0.0
 	for i := 1; i <= period; i++ {
 		sum += trs[i]
-
-		bar.Add(1)
-		time.Sleep(6 * time.Millisecond)
 	}
 	atr[period] = sum / float64(period)
 
 	for i := period + 1; i < len(closes); i++ {
 		atr[i] = (atr[i-1]*(float64(period-1)) + trs[i]) / float64(period)
-
-		bar.Add(1)
-		time.Sleep(6 * time.Millisecond)
 	}
 
-	bar.Finish()
 	return atr
 }
 
 /*
 StochRSI < 0.2 → 超卖 → 可能买入
 StochRSI > 0.8 → 超买 → 可能卖出
+窗口为最近 period+1 个 RSI，RSI 全部就绪后（第 2*period 根）起有值
 */
 func CalculateStochRSI(prices []float64, period int) []float64 {
-	bar := NewTaggedProgressBar(len(prices), period)
-
 	rsi := CalculateRSI(prices, period)
-	stochRsi := make([]float64, len(rsi))
+	stochRsi := nanSeries(len(rsi))
 
-	for i := period; i < len(rsi); i++ {
+	for i := StochRSIWarmup(period); i < len(rsi); i++ {
 		lowest := rsi[i-period]
 		highest := rsi[i-period]
 		for j := i - period + 1; j <= i; j++ {
@@ -514,55 +481,108 @@ func CalculateStochRSI(prices []float64, period int) []float64 {
 		} else {
 			stochRsi[i] = (rsi[i] - lowest) / (highest - lowest)
 		}
-
-		bar.Add(1)
-		time.Sleep(6 * time.Millisecond)
 	}
 
-	bar.Finish()
 	return stochRsi
 }
 
+/*
+StochRSI %K / %D：%K = SMA(StochRSI, smoothK)，%D = SMA(%K, smoothD)，常用 3 / 3
+超卖区 %K 上穿 %D → 金叉，买入信号
+超买区 %K 下穿 %D → 死叉，卖出信号
+*/
+func CalculateStochRSIKD(prices []float64, period, smoothK, smoothD int) StochRSIKD {
+	raw := CalculateStochRSI(prices, period)
+	start := StochRSIWarmup(period)
+	k := smoothSMA(raw, start, smoothK)
+	d := smoothSMA(k, start+max(smoothK, 1)-1, smoothD)
+	return StochRSIKD{K: k, D: d}
+}
+
+// smoothSMA 从 start 开始对 series 做 period 简单移动平均，之前为 NaN
+func smoothSMA(series []float64, start, period int) []float64 {
+	out := nanSeries(len(series))
+	if period <= 1 {
+		copy(out, series)
+		return out
+	}
+	sum := 0.0
+	for i := start; i < len(series); i++ {
+		sum += series[i]
+		if i-period >= start {
+			sum -= series[i-period]
+		}
+		if i-start+1 >= period {
+			out[i] = sum / float64(period)
+		}
+	}
+	return out
+}
+
 /*
 CCI > +100 → 多头强势（买入）
 CCI < -100 → 空头强势（卖出）
+平均绝对偏差依赖当前均值，无法滑动更新，每根遍历窗口求均值与偏差，整体为 O(n·period)
+均值也逐窗口求和而非滑动和：横盘窗口里均值与典型价精确相等，偏差为 0，不会因累加误差得到 ±66.7 这类伪信号
 */
 func CalculateCCI(highs, lows, closes []float64, period int) []float64 {
-	bar := NewTaggedProgressBar(len(closes), period)
-
 	n := len(closes)
-	cci := make([]float64, n)
+	cci := nanSeries(n)
 
-	for i := period - 1; i < n; i++ {
-		typicalPrices := []float64{}
-		for j := i - period + 1; j <= i; j++ {
-			typicalPrice := (highs[j] + lows[j] + closes[j]) / 3
-			typicalPrices = append(typicalPrices, typicalPrice)
+	typicalPrices := make([]float64, n)
+	for i := 0; i < n; i++ {
+		typicalPrices[i] = (highs[i] + lows[i] + closes[i]) / 3
+		if i < period-1 {
+			continue
 		}
+		cci[i] = cciAt(typicalPrices[i-period+1 : i+1])
+	}
 
-		sum := 0.0
-		for _, tp := range typicalPrices {
-			sum += tp
-		}
-		meanTP := sum / float64(period)
+	return cci
+}
 
-		meanDeviation := 0.0
-		for _, tp := range typicalPrices {
-			meanDeviation += math.Abs(tp - meanTP)
-		}
-		meanDeviation /= float64(period)
+// cciAt 以窗口最后一个典型价计算 CCI，平均偏差为 0 时返回 0
+func cciAt(window []float64) float64 {
+	n := float64(len(window))
+	meanTP := 0.0
+	for _, tp := range window {
+		meanTP += tp
+	}
+	meanTP /= n
 
-		currentTP := (highs[i] + lows[i] + closes[i]) / 3
-		if meanDeviation != 0 {
-			cci[i] = (currentTP - meanTP) / (0.015 * meanDeviation)
-		} else {
-			cci[i] = 0
-		}
+	meanDeviation := 0.0
+	for _, tp := range window {
+		meanDeviation += math.Abs(tp - meanTP)
+	}
+	meanDeviation /= n
 
-		bar.Add(1)
-		time.Sleep(6 * time.Millisecond)
+	if meanDeviation == 0 {
+		return 0
 	}
+	return (window[len(window)-1] - meanTP) / (0.015 * meanDeviation)
+}
 
-	bar.Finish()
-	return cci
+/*
+Williams %R：(最高 - 收盘) / (最高 - 最低) × -100，取值 -100 ~ 0
+%R < -80 → 超卖，可能买入
+%R > -20 → 超买，可能卖出
+*/
+func CalculateWilliamsR(highs, lows, closes []float64, period int) []float64 {
+	n := len(closes)
+	wr := nanSeries(n)
+
+	highest, lowest := newRollingMax(period), newRollingMin(period)
+	for i := 0; i < n; i++ {
+		highest.push(highs[i])
+		lowest.push(lows[i])
+		if i < period-1 {
+			continue
+		}
+		if highest.value()-lowest.value() == 0 {
+			wr[i] = -50
+		} else {
+			wr[i] = (highest.value() - closes[i]) / (highest.value() - lowest.value()) * -100
+		}
+	}
+	return wr
 }
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+/*
+滚动窗口版指标与 indicator_naive_test.go 中逐窗口重扫的参考实现对比：
+每个 Benchmark 在同一组 20,000 根 K 线上分别跑 rolling / naive，两者 ns/op 之比即提速
+CCI 例外：平均绝对偏差仍需遍历窗口，两种实现都是 O(n·period)，差别只在不再逐根分配窗口切片
+*/
+
+// benchBars 基准测试用的 K 线数量
+const benchBars = 20000
+
+func benchCandles(n int) []Candle {
+	r := rand.New(rand.NewSource(42))
+	candles := make([]Candle, n)
+	p := 10.0
+	for i := range candles {
+		o := p
+		p = math.Max(0.01, p*(1+0.02*r.NormFloat64()))
+		candles[i] = Candle{
+			Open:   o,
+			High:   math.Max(o, p) * (1 + 0.01*r.Float64()),
+			Low:    math.Min(o, p) * (1 - 0.01*r.Float64()),
+			Close:  p,
+			Volume: float64(1000 + r.Intn(100000)),
+		}
+	}
+	return candles
+}
+
+// benchPeriods 常用周期与长周期，长周期下逐窗口重扫的开销更明显
+var benchPeriods = []int{20, 100}
+
+// benchRollingVsNaive 每个周期下依次运行 rolling 与 naive 两个子基准
+func benchRollingVsNaive(b *testing.B, rolling, naive func(period int)) {
+	benchCompare(b, "rolling", rolling, naive)
+}
+
+// benchCompare 同 benchRollingVsNaive，name 为当前实现的子基准名
+func benchCompare(b *testing.B, name string, current, naive func(period int)) {
+	for _, period := range benchPeriods {
+		b.Run(fmt.Sprintf("%s/period=%d", name, period), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				current(period)
+			}
+		})
+		b.Run(fmt.Sprintf("naive/period=%d", period), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				naive(period)
+			}
+		})
+	}
+}
+
+// BenchmarkCalculateCCI 不是滚动窗口实现：current 与 naive 同为 O(n·period)，只差 naive 每根重建窗口切片的分配
+func BenchmarkCalculateCCI(b *testing.B) {
+	highs, lows, closes := splitCandles(benchCandles(benchBars))
+	benchCompare(b, "current",
+		func(period int) { CalculateCCI(highs, lows, closes, period) },
+		func(period int) { naiveCCI(highs, lows, closes, period) })
+}
+
+func BenchmarkCalculateBollinger(b *testing.B) {
+	_, _, closes := splitCandles(benchCandles(benchBars))
+	benchRollingVsNaive(b,
+		func(period int) { CalculateBollinger(closes, period) },
+		func(period int) { naiveBollinger(closes, period) })
+}
+
+func BenchmarkCalculateKDJ(b *testing.B) {
+	highs, lows, closes := splitCandles(benchCandles(benchBars))
+	benchRollingVsNaive(b,
+		func(period int) { CalculateKDJ(highs, lows, closes, period) },
+		func(period int) { naiveKDJ(highs, lows, closes, period) })
+}
+
+// BenchmarkCalculateIchimoku period 作为基准线周期，转换线 / 先行带 B 取常用比例 9:26:52
+func BenchmarkCalculateIchimoku(b *testing.B) {
+	highs, lows, closes := splitCandles(benchCandles(benchBars))
+	periods := func(kijun int) (int, int, int) { return max(1, kijun*9/26), kijun, kijun * 2 }
+	benchRollingVsNaive(b,
+		func(period int) {
+			tenkan, kijun, senkouB := periods(period)
+			CalculateIchimoku(highs, lows, closes, tenkan, kijun, senkouB, kijun)
+		},
+		func(period int) {
+			tenkan, kijun, senkouB := periods(period)
+			naiveIchimoku(highs, lows, closes, tenkan, kijun, senkouB, kijun)
+		})
+}
+
+func BenchmarkCalculateCR(b *testing.B) {
+	candles := benchCandles(benchBars)
+	benchRollingVsNaive(b,
+		func(period int) { CalculateCR(candles, period) },
+		func(period int) { naiveCR(candles, period) })
+}
+
+func BenchmarkCalculateWilliamsR(b *testing.B) {
+	highs, lows, closes := splitCandles(benchCandles(benchBars))
+	benchRollingVsNaive(b,
+		func(period int) { CalculateWilliamsR(highs, lows, closes, period) },
+		func(period int) { naiveWilliamsR(highs, lows, closes, period) })
+}
+
+// 滚动窗口原语与逐窗口重扫的对比；周期很短（如 20）时重扫本身就很便宜，滑动求和 / 方差不一定更快
+
+func BenchmarkWindowMax(b *testing.B) {
+	values := testSeries(benchBars, 4)
+	benchRollingVsNaive(b,
+		func(period int) {
+			r := newRollingMax(period)
+			for _, v := range values {
+				r.push(v)
+				_ = r.value()
+			}
+		},
+		func(period int) {
+			for j := range values {
+				_ = naiveExtreme(naiveWindow(values, j, period), true)
+			}
+		})
+}
+
+func BenchmarkWindowSum(b *testing.B) {
+	values := testSeries(benchBars, 5)
+	benchRollingVsNaive(b,
+		func(period int) {
+			r := newRollingSum(period)
+			for _, v := range values {
+				r.push(v)
+			}
+		},
+		func(period int) {
+			for j := range values {
+				sum := 0.0
+				for _, v := range naiveWindow(values, j, period) {
+					sum += v
+				}
+				_ = sum
+			}
+		})
+}
+
+func BenchmarkWindowVariance(b *testing.B) {
+	values := testSeries(benchBars, 6)
+	benchRollingVsNaive(b,
+		func(period int) {
+			r := newRollingStats(period)
+			for _, v := range values {
+				r.push(v)
+				_ = r.variance()
+			}
+		},
+		func(period int) {
+			for j := range values {
+				_, _ = naiveMeanVar(naiveWindow(values, j, period))
+			}
+		})
+}
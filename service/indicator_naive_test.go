@@ -0,0 +1,174 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+/*
+逐窗口重扫（O(n·period)）的参考实现，预热期 / 边界约定与 Calculate* 相同
+用于校验滚动窗口版本的结果，并在 indicator_bench_test.go 中作为提速对比的基线
+*/
+
+// naiveCCI 与重写前一致：每根重新计算并分配窗口内的典型价
+func naiveCCI(highs, lows, closes []float64, period int) []float64 {
+	n := len(closes)
+	cci := nanSeries(n)
+	for i := period - 1; i < n; i++ {
+		window := []float64{}
+		for j := i - period + 1; j <= i; j++ {
+			window = append(window, (highs[j]+lows[j]+closes[j])/3)
+		}
+		mean := 0.0
+		for _, v := range window {
+			mean += v
+		}
+		mean /= float64(period)
+		dev := 0.0
+		for _, v := range window {
+			dev += math.Abs(v - mean)
+		}
+		dev /= float64(period)
+		cci[i] = 0
+		if dev != 0 {
+			cci[i] = (window[period-1] - mean) / (0.015 * dev)
+		}
+	}
+	return cci
+}
+
+func naiveBollinger(prices []float64, period int) BollingerBand {
+	n := len(prices)
+	lower, upper := nanSeries(n), nanSeries(n)
+	for i := period - 1; i < n; i++ {
+		mean, variance := naiveMeanVar(prices[i-period+1 : i+1])
+		stddev := math.Sqrt(variance)
+		lower[i], upper[i] = mean-2*stddev, mean+2*stddev
+	}
+	return BollingerBand{LowerBand: lower, MidBand: CalculateEMA(prices, period), UpperBand: upper}
+}
+
+func naiveKDJ(highs, lows, closes []float64, period int) []KDJValue {
+	n := len(closes)
+	kdj := make([]KDJValue, n)
+	for i := range kdj {
+		kdj[i] = KDJValue{K: math.NaN(), D: math.NaN(), J: math.NaN()}
+	}
+	k, d := 50.0, 50.0
+	for i := period - 1; i < n; i++ {
+		high := naiveExtreme(highs[i-period+1:i+1], true)
+		low := naiveExtreme(lows[i-period+1:i+1], false)
+		if high == low {
+			continue
+		}
+		rsv := (closes[i] - low) / (high - low) * 100
+		k = k*2/3 + rsv/3
+		d = d*2/3 + k/3
+		kdj[i] = KDJValue{K: k, D: d, J: 3*k - 2*d}
+	}
+	return kdj
+}
+
+func naiveIchimokuBaseLine(highs, lows []float64, period int) []float64 {
+	out := nanSeries(len(highs))
+	for i := period - 1; i < len(highs); i++ {
+		out[i] = (naiveExtreme(highs[i-period+1:i+1], true) + naiveExtreme(lows[i-period+1:i+1], false)) / 2
+	}
+	return out
+}
+
+func naiveIchimoku(highs, lows, closes []float64, tenkanPeriod, kijunPeriod, senkouBPeriod, displacement int) Ichimoku {
+	n := len(closes)
+	tenkan := naiveIchimokuBaseLine(highs, lows, tenkanPeriod)
+	kijun := naiveIchimokuBaseLine(highs, lows, kijunPeriod)
+	spanB := naiveIchimokuBaseLine(highs, lows, senkouBPeriod)
+	senkouA, senkouB := nanSeries(n+displacement), nanSeries(n+displacement)
+	for i := 0; i < n; i++ {
+		senkouA[i+displacement] = (tenkan[i] + kijun[i]) / 2
+		senkouB[i+displacement] = spanB[i]
+	}
+	chikou := nanSeries(n)
+	for i := displacement; i < n; i++ {
+		chikou[i-displacement] = closes[i]
+	}
+	return Ichimoku{Tenkan: tenkan, Kijun: kijun, SenkouA: senkouA, SenkouB: senkouB, Chikou: chikou, Displacement: displacement}
+}
+
+func naiveCR(candles []Candle, period int) []float64 {
+	n := len(candles)
+	cr := nanSeries(n)
+	for i := period; i < n; i++ {
+		hm, lm := 0.0, 0.0
+		for j := i - period + 1; j <= i; j++ {
+			mp := (candles[j-1].High + candles[j-1].Low) / 2
+			hm += math.Max(0, candles[j].High-mp)
+			lm += math.Max(0, mp-candles[j].Low)
+		}
+		cr[i] = 0
+		if lm != 0 {
+			cr[i] = hm / lm * 100
+		}
+	}
+	return cr
+}
+
+func naiveWilliamsR(highs, lows, closes []float64, period int) []float64 {
+	wr := nanSeries(len(closes))
+	for i := period - 1; i < len(closes); i++ {
+		high := naiveExtreme(highs[i-period+1:i+1], true)
+		low := naiveExtreme(lows[i-period+1:i+1], false)
+		if high == low {
+			wr[i] = -50
+		} else {
+			wr[i] = (high - closes[i]) / (high - low) * -100
+		}
+	}
+	return wr
+}
+
+// sameSeries 求和类指标的累加顺序不同，允许 1e-9 的相对误差；NaN 位置必须一致
+func sameSeries(t *testing.T, name string, got, want []float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: len %d, want %d", name, len(got), len(want))
+	}
+	for i := range want {
+		if math.IsNaN(got[i]) != math.IsNaN(want[i]) || (!math.IsNaN(want[i]) && !closeTo(got[i], want[i])) {
+			t.Fatalf("%s bar %d: %v, want %v", name, i, got[i], want[i])
+		}
+	}
+}
+
+func TestRollingIndicatorsMatchNaive(t *testing.T) {
+	candles := append(loadTestCandles(t, "testdata/candles.csv"), benchCandles(3000)...)
+	highs, lows, closes := splitCandles(candles)
+
+	for _, period := range []int{9, 20, 52} {
+		t.Run(fmt.Sprintf("period=%d", period), func(t *testing.T) {
+			sameSeries(t, "cci", CalculateCCI(highs, lows, closes, period), naiveCCI(highs, lows, closes, period))
+
+			bb, naiveBB := CalculateBollinger(closes, period), naiveBollinger(closes, period)
+			sameSeries(t, "bollinger lower", bb.LowerBand, naiveBB.LowerBand)
+			sameSeries(t, "bollinger upper", bb.UpperBand, naiveBB.UpperBand)
+
+			kdj, naive := CalculateKDJ(highs, lows, closes, period), naiveKDJ(highs, lows, closes, period)
+			for i := range kdj {
+				if !sameFloat(kdj[i].K, naive[i].K) || !sameFloat(kdj[i].D, naive[i].D) || !sameFloat(kdj[i].J, naive[i].J) {
+					t.Fatalf("kdj bar %d: %+v, want %+v", i, kdj[i], naive[i])
+				}
+			}
+
+			sameSeries(t, "ichimoku baseline", CalculateIchimokuBaseLine(highs, lows, period), naiveIchimokuBaseLine(highs, lows, period))
+			sameSeries(t, "cr", CalculateCR(candles, period), naiveCR(candles, period))
+			sameSeries(t, "williams %r", CalculateWilliamsR(highs, lows, closes, period), naiveWilliamsR(highs, lows, closes, period))
+		})
+	}
+
+	ich, naive := CalculateIchimoku(highs, lows, closes, 9, 26, 52, 26), naiveIchimoku(highs, lows, closes, 9, 26, 52, 26)
+	sameSeries(t, "tenkan", ich.Tenkan, naive.Tenkan)
+	sameSeries(t, "kijun", ich.Kijun, naive.Kijun)
+	sameSeries(t, "senkou a", ich.SenkouA, naive.SenkouA)
+	sameSeries(t, "senkou b", ich.SenkouB, naive.SenkouB)
+	sameSeries(t, "chikou", ich.Chikou, naive.Chikou)
+}
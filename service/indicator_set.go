@@ -0,0 +1,146 @@
+package service
+
+import "fmt"
+
+/*
+IndicatorSet 一组 K 线上按参数缓存的指标序列，供注册的 Evaluator 共享
+同一参数的指标只计算一次（例如 rsi 与 mtf 共用 RSI(14)），不支持并发访问
+*/
+type IndicatorSet struct {
+	Candles []Candle
+	Opens   []float64
+	Highs   []float64
+	Lows    []float64
+	Closes  []float64
+
+	cache map[string]any
+}
+
+func NewIndicatorSet(candles []Candle) *IndicatorSet {
+	s := &IndicatorSet{
+		Candles: candles,
+		Opens:   make([]float64, len(candles)),
+		Highs:   make([]float64, len(candles)),
+		Lows:    make([]float64, len(candles)),
+		Closes:  make([]float64, len(candles)),
+		cache:   map[string]any{},
+	}
+	for i, c := range candles {
+		s.Opens[i] = c.Open
+		s.Highs[i] = c.High
+		s.Lows[i] = c.Low
+		s.Closes[i] = c.Close
+	}
+	return s
+}
+
+func (s *IndicatorSet) Len() int {
+	return len(s.Candles)
+}
+
+// memo 按 key 缓存 calc 的结果
+func memo[T any](s *IndicatorSet, key string, calc func() T) T {
+	if v, ok := s.cache[key]; ok {
+		return v.(T)
+	}
+	v := calc()
+	s.cache[key] = v
+	return v
+}
+
+func (s *IndicatorSet) RSI(period int) []float64 {
+	return memo(s, fmt.Sprintf("rsi:%d", period), func() []float64 { return CalculateRSI(s.Closes, period) })
+}
+
+func (s *IndicatorSet) StochRSI(period int) []float64 {
+	return memo(s, fmt.Sprintf("stoch_rsi:%d", period), func() []float64 { return CalculateStochRSI(s.Closes, period) })
+}
+
+func (s *IndicatorSet) StochRSIKD(period, smoothK, smoothD int) StochRSIKD {
+	return memo(s, fmt.Sprintf("stoch_rsi_kd:%d:%d:%d", period, smoothK, smoothD), func() StochRSIKD {
+		return CalculateStochRSIKD(s.Closes, period, smoothK, smoothD)
+	})
+}
+
+func (s *IndicatorSet) CCI(period int) []float64 {
+	return memo(s, fmt.Sprintf("cci:%d", period), func() []float64 { return CalculateCCI(s.Highs, s.Lows, s.Closes, period) })
+}
+
+func (s *IndicatorSet) WilliamsR(period int) []float64 {
+	return memo(s, fmt.Sprintf("williams_r:%d", period), func() []float64 { return CalculateWilliamsR(s.Highs, s.Lows, s.Closes, period) })
+}
+
+func (s *IndicatorSet) KDJ(period int) []KDJValue {
+	return memo(s, fmt.Sprintf("kdj:%d", period), func() []KDJValue { return CalculateKDJ(s.Highs, s.Lows, s.Closes, period) })
+}
+
+func (s *IndicatorSet) SAR(af, maxAF float64) []float64 {
+	return memo(s, fmt.Sprintf("sar:%g:%g", af, maxAF), func() []float64 { return CalculateSAR(s.Highs, s.Lows, af, maxAF) })
+}
+
+func (s *IndicatorSet) Bollinger(period int) BollingerBand {
+	return memo(s, fmt.Sprintf("bollinger:%d", period), func() BollingerBand { return CalculateBollinger(s.Closes, period) })
+}
+
+func (s *IndicatorSet) EMA(period int) []float64 {
+	return memo(s, fmt.Sprintf("ema:%d", period), func() []float64 { return CalculateEMA(s.Closes, period) })
+}
+
+func (s *IndicatorSet) MACD() MACD {
+	return memo(s, "macd", func() MACD { return CalculateMACD(s.Closes) })
+}
+
+func (s *IndicatorSet) ATR(period int) []float64 {
+	return memo(s, fmt.Sprintf("atr:%d", period), func() []float64 { return CalculateATR(s.Highs, s.Lows, s.Closes, period) })
+}
+
+func (s *IndicatorSet) VWAP() []float64 {
+	return memo(s, "vwap", func() []float64 { return CalculateVWAP(s.Candles) })
+}
+
+func (s *IndicatorSet) ARBR() ARBR {
+	return memo(s, "arbr", func() ARBR { return CalculateARBR(s.Candles) })
+}
+
+func (s *IndicatorSet) CR(period int) []float64 {
+	return memo(s, fmt.Sprintf("cr:%d", period), func() []float64 { return CalculateCR(s.Candles, period) })
+}
+
+func (s *IndicatorSet) Ichimoku(tenkan, kijun, senkouB, displacement int) Ichimoku {
+	return memo(s, fmt.Sprintf("ichimoku:%d:%d:%d:%d", tenkan, kijun, senkouB, displacement), func() Ichimoku {
+		return CalculateIchimoku(s.Highs, s.Lows, s.Closes, tenkan, kijun, senkouB, displacement)
+	})
+}
+
+func (s *IndicatorSet) KC(period int) KC {
+	return memo(s, fmt.Sprintf("kc:%d", period), func() KC { return CalculateKeltnerChannel(s.Highs, s.Lows, s.Closes, period) })
+}
+
+func (s *IndicatorSet) TDSequential() []int {
+	return memo(s, "td_sequential", func() []int { return CalculateTDSequential(s.Closes) })
+}
+
+func (s *IndicatorSet) OBV() []float64 {
+	return memo(s, "obv", func() []float64 { return CalculateOBV(s.Candles) })
+}
+
+func (s *IndicatorSet) MFI(period int) []float64 {
+	return memo(s, fmt.Sprintf("mfi:%d", period), func() []float64 { return CalculateMFI(s.Candles, period) })
+}
+
+func (s *IndicatorSet) CMF(period int) []float64 {
+	return memo(s, fmt.Sprintf("cmf:%d", period), func() []float64 { return CalculateCMF(s.Candles, period) })
+}
+
+func (s *IndicatorSet) VolumeSMA(period int) []float64 {
+	return memo(s, fmt.Sprintf("volume_sma:%d", period), func() []float64 { return CalculateVolumeSMA(s.Candles, period) })
+}
+
+func (s *IndicatorSet) VolumeSpike(period int) []float64 {
+	return memo(s, fmt.Sprintf("volume_spike:%d", period), func() []float64 { return CalculateVolumeSpike(s.Candles, period) })
+}
+
+// HTF 高周期指标（已对齐到日线）
+func (s *IndicatorSet) HTF(tf Timeframe) *HigherTimeframe {
+	return memo(s, "htf:"+tf.String(), func() *HigherTimeframe { return CalculateHigherTimeframe(s.Candles, tf) })
+}
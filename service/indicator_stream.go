@@ -0,0 +1,383 @@
+package service
+
+import "math"
+
+/*
+流式（增量）指标：每来一根新 K 线调用一次 Update，返回该 bar 的指标值
+与对应的 Calculate* 批量函数逐 bar 结果完全一致（包括预热期返回 NaN 的约定，窗口类指标共用 rolling.go 的累加器），
+实盘逐根打分时无需每根都从头重算整段序列
+*/
+
+/* ---- RSI ---- */
+
+type RSIStream struct {
+	period           int
+	n                int
+	prev             float64
+	gainSum, lossSum float64
+	avgGain, avgLoss float64
+}
+
+func NewRSIStream(period int) *RSIStream {
+	return &RSIStream{period: period}
+}
+
+func (s *RSIStream) Update(c Candle) float64 {
+	return s.Add(c.Close)
+}
+
+// Add 按收盘价更新，前 period 根返回 NaN
+func (s *RSIStream) Add(price float64) float64 {
+	i := s.n
+	s.n++
+	if i == 0 {
+		s.prev = price
+		return math.NaN()
+	}
+	change := price - s.prev
+	s.prev = price
+
+	p := float64(s.period)
+	if i <= s.period {
+		if change >= 0 {
+			s.gainSum += change
+		} else {
+			s.lossSum -= change
+		}
+		if i < s.period {
+			return math.NaN()
+		}
+		s.avgGain = s.gainSum / p
+		s.avgLoss = s.lossSum / p
+	} else if change >= 0 {
+		s.avgGain = (s.avgGain*(p-1) + change) / p
+		s.avgLoss = (s.avgLoss * (p - 1)) / p
+	} else {
+		s.avgGain = (s.avgGain * (p - 1)) / p
+		s.avgLoss = (s.avgLoss*(p-1) - change) / p
+	}
+
+	if s.avgLoss == 0 {
+		return 100
+	}
+	return 100 - (100 / (1 + s.avgGain/s.avgLoss))
+}
+
+/* ---- EMA ---- */
+
+type EMAStream struct {
+	period int
+	n      int
+	k      float64
+	sum    float64
+	value  float64
+}
+
+func NewEMAStream(period int) *EMAStream {
+	return &EMAStream{period: period, k: 2.0 / (float64(period) + 1.0)}
+}
+
+func (s *EMAStream) Update(c Candle) float64 {
+	return s.Add(c.Close)
+}
+
+// Add 第 period 个有效值起有值（初始值为 SMA），之前返回 NaN；开头的 NaN 输入不计数
+func (s *EMAStream) Add(v float64) float64 {
+	if s.n == 0 && math.IsNaN(v) {
+		return math.NaN()
+	}
+	i := s.n
+	s.n++
+	switch {
+	case i < s.period-1:
+		s.sum += v
+		return math.NaN()
+	case i == s.period-1:
+		s.sum += v
+		s.value = s.sum / float64(s.period)
+	default:
+		s.value = v*s.k + s.value*(1-s.k)
+	}
+	return s.value
+}
+
+/* ---- MACD (12, 26, 9) ---- */
+
+type MACDStream struct {
+	fast, slow, signal *EMAStream
+}
+
+func NewMACDStream() *MACDStream {
+	return &MACDStream{fast: NewEMAStream(12), slow: NewEMAStream(26), signal: NewEMAStream(9)}
+}
+
+func (s *MACDStream) Update(c Candle) MACDValue {
+	line := s.fast.Add(c.Close) - s.slow.Add(c.Close)
+	signal := s.signal.Add(line)
+	return MACDValue{MACD: line, Signal: signal, Histogram: line - signal}
+}
+
+/* ---- ATR ---- */
+
+type ATRStream struct {
+	period    int
+	n         int
+	prevClose float64
+	sum       float64
+	value     float64
+}
+
+func NewATRStream(period int) *ATRStream {
+	return &ATRStream{period: period}
+}
+
+// Update 前 period 根返回 NaN
+func (s *ATRStream) Update(c Candle) float64 {
+	i := s.n
+	s.n++
+	if i == 0 {
+		s.prevClose = c.Close
+		return math.NaN()
+	}
+	tr := math.Max(c.High-c.Low, math.Max(math.Abs(c.High-s.prevClose), math.Abs(c.Low-s.prevClose)))
+	s.prevClose = c.Close
+
+	switch {
+	case i < s.period:
+		s.sum += tr
+		return math.NaN()
+	case i == s.period:
+		s.sum += tr
+		s.value = s.sum / float64(s.period)
+	default:
+		s.value = (s.value*(float64(s.period-1)) + tr) / float64(s.period)
+	}
+	return s.value
+}
+
+/* ---- Bollinger ---- */
+
+type BollingerStream struct {
+	mid   *EMAStream
+	stats *rollingStats
+}
+
+func NewBollingerStream(period int) *BollingerStream {
+	return &BollingerStream{mid: NewEMAStream(period), stats: newRollingStats(period)}
+}
+
+// Update 中轨为 EMA，上下轨为 SMA ± 2 倍标准差；窗口未满时均为 NaN
+func (s *BollingerStream) Update(c Candle) BollingerValue {
+	mid := s.mid.Add(c.Close)
+	s.stats.push(c.Close)
+	if !s.stats.full() {
+		return BollingerValue{Lower: math.NaN(), Mid: mid, Upper: math.NaN()}
+	}
+
+	stddev := math.Sqrt(s.stats.variance())
+	return BollingerValue{Lower: s.stats.mean - 2*stddev, Mid: mid, Upper: s.stats.mean + 2*stddev}
+}
+
+/* ---- KDJ ---- */
+
+type KDJStream struct {
+	highest, lowest *rollingExtreme
+	k, d            float64
+}
+
+func NewKDJStream(period int) *KDJStream {
+	return &KDJStream{highest: newRollingMax(period), lowest: newRollingMin(period), k: 50, d: 50}
+}
+
+// Update 窗口未满或窗口内最高价等于最低价时 K / D / J 为 NaN
+func (s *KDJStream) Update(c Candle) KDJValue {
+	s.highest.push(c.High)
+	s.lowest.push(c.Low)
+	high, low := s.highest.value(), s.lowest.value()
+	if !s.highest.full() || high == low {
+		return KDJValue{K: math.NaN(), D: math.NaN(), J: math.NaN()}
+	}
+
+	rsv := (c.Close - low) / (high - low) * 100
+	s.k = s.k*2/3 + rsv/3
+	s.d = s.d*2/3 + s.k/3
+	return KDJValue{K: s.k, D: s.d, J: 3*s.k - 2*s.d}
+}
+
+/* ---- SAR ---- */
+
+type SARStream struct {
+	accel, maxAccel float64
+	n               int
+	sar, af         float64
+	highest, lowest float64
+	uptrend         bool
+	first           Candle // 第一根，与第二根一起确定初始趋势
+}
+
+func NewSARStream(accelerationFactor, maxAccelerationFactor float64) *SARStream {
+	return &SARStream{accel: accelerationFactor, maxAccel: maxAccelerationFactor}
+}
+
+// Update 第一根返回 NaN，第二根按 CalculateSAR 的方式起算
+func (s *SARStream) Update(c Candle) float64 {
+	i := s.n
+	s.n++
+	switch i {
+	case 0:
+		s.first = c
+		return math.NaN()
+	case 1:
+		s.uptrend, s.sar = sarSeed(s.first.High, s.first.Low, c.High, c.Low)
+		s.af = s.accel
+		s.highest, s.lowest = c.High, c.Low
+		return s.sar
+	}
+
+	if s.uptrend {
+		s.sar = s.sar + s.af*(s.highest-s.sar)
+		if c.Low < s.sar {
+			s.uptrend = false
+			s.sar = s.highest
+			s.af = s.accel
+			s.lowest = c.Low
+		} else if c.High > s.highest {
+			s.highest = c.High
+			s.af = math.Min(s.af+s.accel, s.maxAccel)
+		}
+	} else {
+		s.sar = s.sar + s.af*(s.lowest-s.sar)
+		if c.High > s.sar {
+			s.uptrend = true
+			s.sar = s.lowest
+			s.af = s.accel
+			s.highest = c.High
+		} else if c.Low < s.lowest {
+			s.lowest = c.Low
+			s.af = math.Min(s.af+s.accel, s.maxAccel)
+		}
+	}
+	return s.sar
+}
+
+/* ---- CCI ---- */
+
+type CCIStream struct {
+	window *ringWindow // 典型价格 (H+L+C)/3
+	buf    []float64   // 按时间顺序展开的窗口，复用避免逐根分配
+}
+
+func NewCCIStream(period int) *CCIStream {
+	return &CCIStream{window: newRingWindow(period), buf: make([]float64, period)}
+}
+
+// Update 窗口未满时返回 NaN，平均偏差为 0 时返回 0；每根需遍历窗口，单次为 O(period)
+func (s *CCIStream) Update(c Candle) float64 {
+	s.window.push((c.High + c.Low + c.Close) / 3)
+	if !s.window.full() {
+		return math.NaN()
+	}
+	for i := range s.buf {
+		s.buf[i] = s.window.at(i)
+	}
+	return cciAt(s.buf)
+}
+
+/* ---- StochRSI ---- */
+
+type StochRSIStream struct {
+	period int
+	n      int
+	rsi    *RSIStream
+	window *ringWindow // 最近 period+1 个 RSI
+}
+
+func NewStochRSIStream(period int) *StochRSIStream {
+	return &StochRSIStream{period: period, rsi: NewRSIStream(period), window: newRingWindow(period + 1)}
+}
+
+// Update 取值 0~1，前 2*period 根返回 NaN
+func (s *StochRSIStream) Update(c Candle) float64 {
+	rsi := s.rsi.Add(c.Close)
+	s.window.push(rsi)
+	i := s.n
+	s.n++
+	if i < StochRSIWarmup(s.period) {
+		return math.NaN()
+	}
+
+	lowest, highest := s.window.at(0), s.window.at(0)
+	for j := 1; j < s.window.size; j++ {
+		lowest = math.Min(lowest, s.window.at(j))
+		highest = math.Max(highest, s.window.at(j))
+	}
+	if highest-lowest == 0 {
+		return 0
+	}
+	return (rsi - lowest) / (highest - lowest)
+}
+
+/* ---- CR ---- */
+
+type CRStream struct {
+	period  int
+	n       int
+	prevMid float64
+	hm, lm  *rollingSum // 每根 max(0, H - 前一根中价) / max(0, 前一根中价 - L)
+}
+
+func NewCRStream(period int) *CRStream {
+	return &CRStream{period: period, hm: newRollingSum(period), lm: newRollingSum(period)}
+}
+
+// Update 前 period 根返回 NaN
+func (s *CRStream) Update(c Candle) float64 {
+	i := s.n
+	s.n++
+	mid := s.prevMid
+	s.prevMid = (c.High + c.Low) / 2
+	if i == 0 {
+		return math.NaN()
+	}
+
+	s.hm.push(math.Max(0, c.High-mid))
+	s.lm.push(math.Max(0, mid-c.Low))
+	if i < s.period {
+		return math.NaN()
+	}
+	if s.lm.sum == 0 {
+		return 0
+	}
+	return s.hm.sum / s.lm.sum * 100
+}
+
+/* ---- ARBR ---- */
+
+type ARBRStream struct {
+	n         int
+	prevClose float64
+}
+
+func NewARBRStream() *ARBRStream {
+	return &ARBRStream{}
+}
+
+// Update 第一根返回 NaN
+func (s *ARBRStream) Update(c Candle) ARBRValue {
+	i := s.n
+	s.n++
+	prevClose := s.prevClose
+	s.prevClose = c.Close
+	if i == 0 {
+		return ARBRValue{AR: math.NaN(), BR: math.NaN()}
+	}
+
+	var v ARBRValue
+	if ol := c.Open - c.Low; ol != 0 {
+		v.AR = (c.High - c.Open) / ol * 100
+	}
+	if lc := math.Abs(prevClose - c.Low); lc != 0 {
+		v.BR = math.Abs(c.High-prevClose) / lc * 100
+	}
+	return v
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"encoding/csv"
+	"math"
+	"os"
+	"strconv"
+	"testing"
+)
+
+// loadTestCandles 读取 testdata 下的 K 线夹具：date,open,high,low,close,volume
+func loadTestCandles(t testing.TB, path string) []Candle {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	candles := make([]Candle, 0, len(rows))
+	for _, row := range rows[1:] {
+		var v [5]float64
+		for j := range v {
+			if v[j], err = strconv.ParseFloat(row[j+1], 64); err != nil {
+				t.Fatalf("parse %s: %v", path, err)
+			}
+		}
+		candles = append(candles, Candle{Date: row[0], Open: v[0], High: v[1], Low: v[2], Close: v[3], AdjClose: v[3], Volume: v[4]})
+	}
+	return candles
+}
+
+func splitCandles(candles []Candle) (highs, lows, closes []float64) {
+	for _, c := range candles {
+		highs = append(highs, c.High)
+		lows = append(lows, c.Low)
+		closes = append(closes, c.Close)
+	}
+	return
+}
+
+// sameFloat 逐位相等，NaN 与 NaN 视为相等
+func sameFloat(a, b float64) bool {
+	return a == b || (math.IsNaN(a) && math.IsNaN(b))
+}
+
+// TestStreamsMatchBatch 逐根 Update 的结果与 Calculate* 批量结果完全一致（含预热期 NaN 的位置）
+func TestStreamsMatchBatch(t *testing.T) {
+	candles := loadTestCandles(t, "testdata/candles.csv")
+	highs, lows, closes := splitCandles(candles)
+
+	tests := []struct {
+		name   string
+		fields []string
+		batch  [][]float64 // 与 fields 一一对应
+		stream func() func(Candle) []float64
+	}{
+		{
+			name:   "RSI",
+			fields: []string{"rsi"},
+			batch:  [][]float64{CalculateRSI(closes, 14)},
+			stream: func() func(Candle) []float64 {
+				s := NewRSIStream(14)
+				return func(c Candle) []float64 { return []float64{s.Update(c)} }
+			},
+		},
+		{
+			name:   "EMA",
+			fields: []string{"ema"},
+			batch:  [][]float64{CalculateEMA(closes, 12)},
+			stream: func() func(Candle) []float64 {
+				s := NewEMAStream(12)
+				return func(c Candle) []float64 { return []float64{s.Update(c)} }
+			},
+		},
+		{
+			name:   "MACD",
+			fields: []string{"macd", "signal", "histogram"},
+			batch: func() [][]float64 {
+				m := CalculateMACD(closes)
+				return [][]float64{m.MACDLine, m.SignalLine, m.Histogram}
+			}(),
+			stream: func() func(Candle) []float64 {
+				s := NewMACDStream()
+				return func(c Candle) []float64 {
+					v := s.Update(c)
+					return []float64{v.MACD, v.Signal, v.Histogram}
+				}
+			},
+		},
+		{
+			name:   "ATR",
+			fields: []string{"atr"},
+			batch:  [][]float64{CalculateATR(highs, lows, closes, 14)},
+			stream: func() func(Candle) []float64 {
+				s := NewATRStream(14)
+				return func(c Candle) []float64 { return []float64{s.Update(c)} }
+			},
+		},
+		{
+			name:   "Bollinger",
+			fields: []string{"lower", "mid", "upper"},
+			batch: func() [][]float64 {
+				bb := CalculateBollinger(closes, 20)
+				return [][]float64{bb.LowerBand, bb.MidBand, bb.UpperBand}
+			}(),
+			stream: func() func(Candle) []float64 {
+				s := NewBollingerStream(20)
+				return func(c Candle) []float64 {
+					v := s.Update(c)
+					return []float64{v.Lower, v.Mid, v.Upper}
+				}
+			},
+		},
+		{
+			name:   "KDJ",
+			fields: []string{"k", "d", "j"},
+			batch: func() [][]float64 {
+				kdj := CalculateKDJ(highs, lows, closes, 9)
+				k, d, j := make([]float64, len(kdj)), make([]float64, len(kdj)), make([]float64, len(kdj))
+				for i, v := range kdj {
+					k[i], d[i], j[i] = v.K, v.D, v.J
+				}
+				return [][]float64{k, d, j}
+			}(),
+			stream: func() func(Candle) []float64 {
+				s := NewKDJStream(9)
+				return func(c Candle) []float64 {
+					v := s.Update(c)
+					return []float64{v.K, v.D, v.J}
+				}
+			},
+		},
+		{
+			name:   "SAR",
+			fields: []string{"sar"},
+			batch:  [][]float64{CalculateSAR(highs, lows, 0.02, 0.2)},
+			stream: func() func(Candle) []float64 {
+				s := NewSARStream(0.02, 0.2)
+				return func(c Candle) []float64 { return []float64{s.Update(c)} }
+			},
+		},
+		{
+			name:   "CCI",
+			fields: []string{"cci"},
+			batch:  [][]float64{CalculateCCI(highs, lows, closes, 20)},
+			stream: func() func(Candle) []float64 {
+				s := NewCCIStream(20)
+				return func(c Candle) []float64 { return []float64{s.Update(c)} }
+			},
+		},
+		{
+			name:   "StochRSI",
+			fields: []string{"stoch_rsi"},
+			batch:  [][]float64{CalculateStochRSI(closes, 14)},
+			stream: func() func(Candle) []float64 {
+				s := NewStochRSIStream(14)
+				return func(c Candle) []float64 { return []float64{s.Update(c)} }
+			},
+		},
+		{
+			name:   "CR",
+			fields: []string{"cr"},
+			batch:  [][]float64{CalculateCR(candles, 26)},
+			stream: func() func(Candle) []float64 {
+				s := NewCRStream(26)
+				return func(c Candle) []float64 { return []float64{s.Update(c)} }
+			},
+		},
+		{
+			name:   "ARBR",
+			fields: []string{"ar", "br"},
+			batch: func() [][]float64 {
+				arbr := CalculateARBR(candles)
+				return [][]float64{arbr.AR, arbr.BR}
+			}(),
+			stream: func() func(Candle) []float64 {
+				s := NewARBRStream()
+				return func(c Candle) []float64 {
+					v := s.Update(c)
+					return []float64{v.AR, v.BR}
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update := tt.stream()
+			for i, c := range candles {
+				got := update(c)
+				for f, name := range tt.fields {
+					if want := tt.batch[f][i]; !sameFloat(got[f], want) {
+						t.Fatalf("%s bar %d (%s): stream %v, batch %v", name, i, c.Date, got[f], want)
+					}
+				}
+			}
+		})
+	}
+}
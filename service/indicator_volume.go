@@ -0,0 +1,223 @@
+package service
+
+import "math"
+
+/*
+成交量类指标：VWAP / OBV / MFI / CMF / 均量 / 放量倍数
+数据源没有成交量（全为 0）时，各指标退化为中性值，评分规则不会触发；预热期为 NaN
+*/
+
+func typicalPrice(c Candle) float64 {
+	return (c.High + c.Low + c.Close) / 3
+}
+
+func hasVolume(candles []Candle) bool {
+	for _, c := range candles {
+		if c.Volume > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// CalculateAnchoredVWAP 从 anchor 开始累计的 VWAP，anchor 之前为 NaN
+func CalculateAnchoredVWAP(candles []Candle, anchor int) []float64 {
+	n := len(candles)
+	vwap := nanSeries(n)
+	withVolume := hasVolume(candles)
+
+	var cumulativePV, cumulativeVolume float64
+	for i := max(anchor, 0); i < n; i++ {
+		volume := candles[i].Volume
+		if !withVolume {
+			volume = 1 // 无成交量数据时等权
+		}
+		cumulativePV += typicalPrice(candles[i]) * volume
+		cumulativeVolume += volume
+		if cumulativeVolume > 0 {
+			vwap[i] = cumulativePV / cumulativeVolume
+		} else {
+			vwap[i] = typicalPrice(candles[i])
+		}
+	}
+	return vwap
+}
+
+// CalculateRollingVWAP 最近 period 根的 VWAP，前 period-1 根为 NaN
+func CalculateRollingVWAP(candles []Candle, period int) []float64 {
+	n := len(candles)
+	vwap := nanSeries(n)
+	withVolume := hasVolume(candles)
+
+	var sumPV, sumVolume float64
+	for i := 0; i < n; i++ {
+		volume := candles[i].Volume
+		if !withVolume {
+			volume = 1
+		}
+		sumPV += typicalPrice(candles[i]) * volume
+		sumVolume += volume
+
+		if i >= period {
+			old := candles[i-period].Volume
+			if !withVolume {
+				old = 1
+			}
+			sumPV -= typicalPrice(candles[i-period]) * old
+			sumVolume -= old
+		}
+		if i >= period-1 {
+			if sumVolume > 0 {
+				vwap[i] = sumPV / sumVolume
+			} else {
+				vwap[i] = typicalPrice(candles[i])
+			}
+		}
+	}
+	return vwap
+}
+
+/*
+OBV：价涨加量、价跌减量
+OBV 与价格同向 → 量价配合；背离 → 趋势可能衰竭
+*/
+func CalculateOBV(candles []Candle) []float64 {
+	n := len(candles)
+	obv := make([]float64, n)
+	for i := 1; i < n; i++ {
+		switch {
+		case candles[i].Close > candles[i-1].Close:
+			obv[i] = obv[i-1] + candles[i].Volume
+		case candles[i].Close < candles[i-1].Close:
+			obv[i] = obv[i-1] - candles[i].Volume
+		default:
+			obv[i] = obv[i-1]
+		}
+	}
+	return obv
+}
+
+/*
+MFI（资金流量指数，带成交量的 RSI）：
+MFI < 20 → 超卖
+MFI > 80 → 超买
+无成交量时为 50（中性），前 period 根为 NaN
+*/
+func CalculateMFI(candles []Candle, period int) []float64 {
+	n := len(candles)
+	mfi := nanSeries(n)
+
+	pos := make([]float64, n)
+	neg := make([]float64, n)
+	for i := 1; i < n; i++ {
+		tp, prev := typicalPrice(candles[i]), typicalPrice(candles[i-1])
+		flow := tp * candles[i].Volume
+		if tp > prev {
+			pos[i] = flow
+		} else if tp < prev {
+			neg[i] = flow
+		}
+	}
+
+	var sumPos, sumNeg float64
+	for i := 1; i < n; i++ {
+		sumPos += pos[i]
+		sumNeg += neg[i]
+		if i > period {
+			sumPos -= pos[i-period]
+			sumNeg -= neg[i-period]
+		}
+		if i < period {
+			continue
+		}
+		switch {
+		case sumPos+sumNeg == 0:
+			mfi[i] = 50
+		case sumNeg == 0:
+			mfi[i] = 100
+		default:
+			mfi[i] = 100 - 100/(1+sumPos/sumNeg)
+		}
+	}
+	return mfi
+}
+
+/*
+CMF（Chaikin Money Flow）：
+CMF > 0.1 → 资金持续流入
+CMF < -0.1 → 资金持续流出
+*/
+func CalculateCMF(candles []Candle, period int) []float64 {
+	n := len(candles)
+	cmf := nanSeries(n)
+	mfv := make([]float64, n)
+	for i, c := range candles {
+		if rng := c.High - c.Low; rng > 0 {
+			mfv[i] = ((c.Close - c.Low) - (c.High - c.Close)) / rng * c.Volume
+		}
+	}
+
+	var sumMFV, sumVolume float64
+	for i := 0; i < n; i++ {
+		sumMFV += mfv[i]
+		sumVolume += candles[i].Volume
+		if i >= period {
+			sumMFV -= mfv[i-period]
+			sumVolume -= candles[i-period].Volume
+		}
+		if i < period-1 {
+			continue
+		}
+		cmf[i] = 0
+		if sumVolume > 0 {
+			cmf[i] = sumMFV / sumVolume
+		}
+	}
+	return cmf
+}
+
+// CalculateVolumeSMA 成交量简单均线，前 period-1 根为 NaN
+func CalculateVolumeSMA(candles []Candle, period int) []float64 {
+	n := len(candles)
+	sma := nanSeries(n)
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		sum += candles[i].Volume
+		if i >= period {
+			sum -= candles[i-period].Volume
+		}
+		if i >= period-1 {
+			sma[i] = sum / float64(period)
+		}
+	}
+	return sma
+}
+
+/*
+放量倍数 = 当日成交量 / 之前 period 根的平均量（不含当日）
+≥ 2 视为放量，前 period 根为 NaN
+*/
+func CalculateVolumeSpike(candles []Candle, period int) []float64 {
+	n := len(candles)
+	ratio := nanSeries(n)
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		if i >= period {
+			ratio[i] = 0
+			if avg := sum / float64(period); avg > 0 {
+				ratio[i] = candles[i].Volume / avg
+			}
+			sum -= candles[i-period].Volume
+		}
+		sum += candles[i].Volume
+	}
+	return ratio
+}
+
+// volumeTrend lookback 根内的变化量
+func volumeTrend(series []float64, index, lookback int) float64 {
+	if index-lookback < 0 {
+		return math.NaN()
+	}
+	return series[index] - series[index-lookback]
+}
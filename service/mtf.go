@@ -0,0 +1,108 @@
+package service
+
+import (
+	"math"
+	"time"
+)
+
+// HigherTimeframe 高周期指标，已按日线下标对齐，长度与日线相同；未就绪的位置为 NaN
+type HigherTimeframe struct {
+	Timeframe Timeframe
+	RSI       []float64
+	StochRSI  []float64
+	MACD      MACD
+}
+
+const htfRSIPeriod = 14
+
+/*
+CalculateHigherTimeframe 由日线合成高周期 K 线，计算 RSI / StochRSI / MACD 后映射回日线
+无未来函数：日线 i 只能看到截止 i 已经走完的高周期 K 线
+是否走完只按日历判断，不看后一根日线是否存在：i 是该周期日历上的最后一个工作日（周五 / 月末）时该周期在 i 收盘时走完，
+否则要等到下一个周期的第一根日线（例如周五休市，该周在下周一才确认走完）
+高周期 K 线数量不足时对应指标全部为 NaN
+*/
+func CalculateHigherTimeframe(candles []Candle, tf Timeframe) *HigherTimeframe {
+	bars, group := ResampleIndex(candles, tf)
+	closes := make([]float64, len(bars))
+	for i, b := range bars {
+		closes[i] = b.Close
+	}
+
+	htf := &HigherTimeframe{Timeframe: tf}
+	nan := nanSeries(len(bars))
+	rsi, stochRsi := nan, nan
+	macd := MACD{MACDLine: nan, SignalLine: nan, Histogram: nan}
+
+	if len(bars) > StochRSIWarmup(htfRSIPeriod) {
+		rsi = CalculateRSI(closes, htfRSIPeriod)
+		stochRsi = CalculateStochRSI(closes, htfRSIPeriod)
+	}
+	if len(bars) > MACDWarmup() {
+		macd = CalculateMACD(closes)
+	}
+
+	completed := completedIndex(candles, group, tf)
+	htf.RSI = alignHTF(rsi, completed, RSIWarmup(htfRSIPeriod))
+	htf.StochRSI = alignHTF(stochRsi, completed, StochRSIWarmup(htfRSIPeriod))
+	htf.MACD = MACD{
+		MACDLine:   alignHTF(macd.MACDLine, completed, MACDWarmup()),
+		SignalLine: alignHTF(macd.SignalLine, completed, MACDWarmup()),
+		Histogram:  alignHTF(macd.Histogram, completed, MACDWarmup()),
+	}
+	return htf
+}
+
+// completedIndex 每根日线收盘时最近一根已走完的高周期 K 线下标，-1 表示还没有
+func completedIndex(candles []Candle, group []int, tf Timeframe) []int {
+	n := max(tf.N, 1)
+	out := make([]int, len(group))
+	periods, lastKey := 0, 0
+	for i, g := range group {
+		if i == 0 || g != group[i-1] {
+			periods = 0
+		}
+
+		var closed bool
+		if tf.Unit == UnitDay {
+			closed = (i+1)%n == 0
+		} else {
+			// 组内已出现的自然周 / 月个数，满 N 个且当天是该周 / 月最后一个工作日时走完
+			key := periodKey(candles[i], tf.Unit)
+			if periods == 0 || key != lastKey {
+				periods++
+			}
+			lastKey = key
+			closed = periods == n && lastWeekdayOfPeriod(candleTime(candles[i]), tf.Unit)
+		}
+
+		if closed {
+			out[i] = g
+		} else {
+			out[i] = g - 1
+		}
+	}
+	return out
+}
+
+// lastWeekdayOfPeriod t 之后的下一个工作日已属于另一个自然周 / 月
+func lastWeekdayOfPeriod(t time.Time, unit TimeframeUnit) bool {
+	next := t.AddDate(0, 0, 1)
+	for next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+		next = next.AddDate(0, 0, 1)
+	}
+	return timePeriodKey(next, unit) != timePeriodKey(t, unit)
+}
+
+// alignHTF 按 completed 映射到日线，高周期下标小于 warmup 时为 NaN
+func alignHTF(values []float64, completed []int, warmup int) []float64 {
+	out := make([]float64, len(completed))
+	for i, k := range completed {
+		if k < warmup || k >= len(values) {
+			out[i] = math.NaN()
+			continue
+		}
+		out[i] = values[k]
+	}
+	return out
+}
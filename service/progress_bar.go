@@ -3,27 +3,40 @@ package service
 import (
 	"fmt"
 	"github.com/schollz/progressbar/v3"
-	"runtime"
 )
 
-func NewTaggedProgressBar(n int, tag int) *progressbar.ProgressBar {
-	fmt.Println("")
+// ProgressFunc 进度回调：stage 为当前阶段（如 Evaluator 名称），done / total 为已完成与总步数
+type ProgressFunc func(stage string, done, total int)
 
-	pc, _, _, _ := runtime.Caller(1) // use Caller(1) to get the calling function's name
-	funcName := runtime.FuncForPC(pc).Name()
-
-	bar := progressbar.NewOptions(n,
-		progressbar.OptionSetDescription(fmt.Sprintf(" ▶ %s | Period:%d", funcName, tag)),
-		progressbar.OptionShowCount(),
-		progressbar.OptionSetWidth(100),
-		progressbar.OptionSetTheme(progressbar.Theme{
-			Saucer:        "#",
-			SaucerHead:    ">",
-			SaucerPadding: "-",
-			BarStart:      "[",
-			BarEnd:        "]",
-		}),
-	)
+// report 回调为 nil 时不做任何事
+func (f ProgressFunc) report(stage string, done, total int) {
+	if f != nil {
+		f(stage, done, total)
+	}
+}
 
-	return bar
+// NewProgressBarFunc 在终端绘制进度条的 ProgressFunc，首次回调时按 total 创建进度条
+func NewProgressBarFunc(title string) ProgressFunc {
+	var bar *progressbar.ProgressBar
+	return func(stage string, done, total int) {
+		if bar == nil {
+			fmt.Println("")
+			bar = progressbar.NewOptions(total,
+				progressbar.OptionShowCount(),
+				progressbar.OptionSetWidth(100),
+				progressbar.OptionSetTheme(progressbar.Theme{
+					Saucer:        "#",
+					SaucerHead:    ">",
+					SaucerPadding: "-",
+					BarStart:      "[",
+					BarEnd:        "]",
+				}),
+			)
+		}
+		bar.Describe(fmt.Sprintf(" ▶ %s | %s", title, stage))
+		_ = bar.Set(done)
+		if done >= total {
+			_ = bar.Finish()
+		}
+	}
 }
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TimeframeUnit int
+
+const (
+	UnitDay   TimeframeUnit = iota // N 根交易日 K 线合成一根
+	UnitWeek                       // 自然周（周一至周五）
+	UnitMonth                      // 自然月
+)
+
+// Timeframe K 线周期，例如 1d / 5d / 1w / 1mo
+type Timeframe struct {
+	Unit TimeframeUnit
+	N    int
+}
+
+var (
+	Daily   = Timeframe{Unit: UnitDay, N: 1}
+	Weekly  = Timeframe{Unit: UnitWeek, N: 1}
+	Monthly = Timeframe{Unit: UnitMonth, N: 1}
+)
+
+func (tf Timeframe) String() string {
+	n := tf.N
+	if n <= 0 {
+		n = 1
+	}
+	switch tf.Unit {
+	case UnitWeek:
+		return fmt.Sprintf("%dw", n)
+	case UnitMonth:
+		return fmt.Sprintf("%dmo", n)
+	default:
+		return fmt.Sprintf("%dd", n)
+	}
+}
+
+// ParseTimeframe 支持 d / w / mo 后缀，数字省略时为 1，如 "w"、"5d"、"1mo"
+func ParseTimeframe(s string) (Timeframe, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	var unit TimeframeUnit
+	var num string
+	switch {
+	case strings.HasSuffix(s, "mo"):
+		unit, num = UnitMonth, strings.TrimSuffix(s, "mo")
+	case strings.HasSuffix(s, "w"):
+		unit, num = UnitWeek, strings.TrimSuffix(s, "w")
+	case strings.HasSuffix(s, "d"):
+		unit, num = UnitDay, strings.TrimSuffix(s, "d")
+	default:
+		return Timeframe{}, fmt.Errorf("unknown timeframe %q, expected e.g. 1d / 5d / 1w / 1mo", s)
+	}
+
+	n := 1
+	if num != "" {
+		var err error
+		if n, err = strconv.Atoi(num); err != nil || n <= 0 {
+			return Timeframe{}, fmt.Errorf("invalid timeframe %q", s)
+		}
+	}
+	return Timeframe{Unit: unit, N: n}, nil
+}
+
+/*
+Resample 将日线合成为更高周期 K 线：
+开盘取第一根，最高/最低取区间极值，收盘（及复权收盘）取最后一根，成交量求和
+周线按 ISO 周（Bursa 周一至周五交易）分组，公共假期自然跳过；月线按自然月分组
+N 周 / N 月从序列第一个周期开始每 N 个周期合并
+合成后 K 线的 Date / Time 为该区间最后一个交易日，即这根 K 线收盘确定的日期
+*/
+func Resample(candles []Candle, tf Timeframe) []Candle {
+	out, _ := ResampleIndex(candles, tf)
+	return out
+}
+
+// ResampleIndex 同 Resample，并返回每根原始 K 线所属的合成 K 线下标
+func ResampleIndex(candles []Candle, tf Timeframe) ([]Candle, []int) {
+	n := tf.N
+	if n <= 0 {
+		n = 1
+	}
+	group := make([]int, len(candles))
+	if len(candles) == 0 {
+		return nil, group
+	}
+
+	var out []Candle
+	var lastKey, periods int
+	for i, c := range candles {
+		key := i / n
+		if tf.Unit != UnitDay {
+			key = periodKey(c, tf.Unit)
+		}
+
+		newPeriod := i == 0 || key != lastKey
+		if newPeriod && i > 0 {
+			periods++
+		}
+		lastKey = key
+		// N 个周 / 月合成一根
+		if newPeriod && (tf.Unit == UnitDay || periods%n == 0) {
+			out = append(out, c)
+			group[i] = len(out) - 1
+			continue
+		}
+
+		bar := &out[len(out)-1]
+		bar.Date = c.Date
+		bar.Time = c.Time
+		bar.High = max(bar.High, c.High)
+		bar.Low = min(bar.Low, c.Low)
+		bar.Close = c.Close
+		bar.AdjClose = c.AdjClose
+		bar.Volume += c.Volume
+		group[i] = len(out) - 1
+	}
+	return out, group
+}
+
+// periodKey 周 / 月分组键：年 * 100 + ISO 周数或月份
+func periodKey(c Candle, unit TimeframeUnit) int {
+	return timePeriodKey(candleTime(c), unit)
+}
+
+func timePeriodKey(t time.Time, unit TimeframeUnit) int {
+	if unit == UnitMonth {
+		return t.Year()*100 + int(t.Month())
+	}
+	year, week := t.ISOWeek()
+	return year*100 + week
+}
+
+// candleTime 优先使用 Date 字符串，避免时区影响星期几的判断
+func candleTime(c Candle) time.Time {
+	if t, err := time.Parse("2006-01-02", c.Date); err == nil {
+		return t
+	}
+	return c.Time
+}
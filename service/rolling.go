@@ -0,0 +1,201 @@
+package service
+
+/*
+滚动窗口工具：单调队列求窗口最大 / 最小值，滑动求和，Welford 滑动方差
+每根 bar 均摊 O(1)，批量 Calculate* 与流式 *Stream 共用同一套累加顺序，两者结果逐位一致
+*/
+
+// ringWindow 定长滑动窗口，按从旧到新的顺序遍历
+type ringWindow struct {
+	buf  []float64
+	next int
+	size int
+}
+
+func newRingWindow(n int) *ringWindow {
+	return &ringWindow{buf: make([]float64, n)}
+}
+
+func (w *ringWindow) push(v float64) {
+	w.buf[w.next] = v
+	w.next = (w.next + 1) % len(w.buf)
+	if w.size < len(w.buf) {
+		w.size++
+	}
+}
+
+func (w *ringWindow) full() bool {
+	return w.size == len(w.buf)
+}
+
+// at 窗口内第 i 个值，0 为最旧
+func (w *ringWindow) at(i int) float64 {
+	return w.buf[(w.next-w.size+i+len(w.buf))%len(w.buf)]
+}
+
+// rollingSum 最近 period 个值的滑动和
+// 窗口每轮转一圈按原顺序重算一次，避免长序列累积误差；窗口内全为 0 时和精确为 0（CR 等以 0 作除数判断）
+type rollingSum struct {
+	window  *ringWindow
+	sum     float64
+	nonzero int
+}
+
+func newRollingSum(period int) *rollingSum {
+	return &rollingSum{window: newRingWindow(period)}
+}
+
+func (r *rollingSum) push(v float64) {
+	if r.window.full() {
+		old := r.window.at(0)
+		r.sum -= old
+		if old != 0 {
+			r.nonzero--
+		}
+	}
+	r.window.push(v)
+	r.sum += v
+	if v != 0 {
+		r.nonzero++
+	}
+
+	switch {
+	case r.nonzero == 0:
+		r.sum = 0
+	case r.window.next == 0:
+		r.sum = 0
+		for i := 0; i < r.window.size; i++ {
+			r.sum += r.window.at(i)
+		}
+	}
+}
+
+func (r *rollingSum) full() bool {
+	return r.window.full()
+}
+
+// rollingStats 最近 period 个值的均值与总体方差（Welford 滑动更新，避免 sum/sumSq 相减的精度损失；每轮转一圈重算一次）
+type rollingStats struct {
+	window   *ringWindow
+	mean, m2 float64
+	run      int // 末尾连续相等值的个数，覆盖整个窗口时均值 / 方差直接取精确值
+}
+
+func newRollingStats(period int) *rollingStats {
+	return &rollingStats{window: newRingWindow(period)}
+}
+
+func (r *rollingStats) push(v float64) {
+	if r.window.size > 0 && v == r.window.at(r.window.size-1) {
+		r.run++
+	} else {
+		r.run = 1
+	}
+	r.update(v)
+	// 窗口内全部相等时方差精确为 0，避免滑动更新的残差经开方放大成非零带宽
+	if r.run >= r.window.size {
+		r.mean, r.m2 = v, 0
+	}
+}
+
+func (r *rollingStats) update(v float64) {
+	if !r.window.full() {
+		r.window.push(v)
+		delta := v - r.mean
+		r.mean += delta / float64(r.window.size)
+		r.m2 += delta * (v - r.mean)
+		return
+	}
+
+	old := r.window.at(0)
+	r.window.push(v)
+	if r.window.next == 0 {
+		r.resync()
+		return
+	}
+	oldMean := r.mean
+	r.mean += (v - old) / float64(r.window.size)
+	r.m2 += (v - old) * (v - r.mean + old - oldMean)
+	if r.m2 < 0 {
+		r.m2 = 0
+	}
+}
+
+// resync 窗口每轮转一圈按两遍法重算均值与方差，消除滑动更新的累积误差
+func (r *rollingStats) resync() {
+	n := r.window.size
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		sum += r.window.at(i)
+	}
+	r.mean = sum / float64(n)
+	r.m2 = 0
+	for i := 0; i < n; i++ {
+		d := r.window.at(i) - r.mean
+		r.m2 += d * d
+	}
+}
+
+func (r *rollingStats) full() bool {
+	return r.window.full()
+}
+
+func (r *rollingStats) variance() float64 {
+	if r.window.size == 0 {
+		return 0
+	}
+	return r.m2 / float64(r.window.size)
+}
+
+// rollingExtreme 单调队列：max 为 true 时求窗口最大值，否则求最小值
+type rollingExtreme struct {
+	period int
+	max    bool
+	seq    int
+	idx    []int
+	vals   []float64
+	head   int
+}
+
+func newRollingMax(period int) *rollingExtreme {
+	return &rollingExtreme{period: period, max: true}
+}
+
+func newRollingMin(period int) *rollingExtreme {
+	return &rollingExtreme{period: period}
+}
+
+func (r *rollingExtreme) push(v float64) {
+	// 队尾不优于 v 的元素不可能再成为窗口极值
+	for len(r.vals) > r.head {
+		back := r.vals[len(r.vals)-1]
+		if (r.max && back > v) || (!r.max && back < v) {
+			break
+		}
+		r.idx = r.idx[:len(r.idx)-1]
+		r.vals = r.vals[:len(r.vals)-1]
+	}
+	r.idx = append(r.idx, r.seq)
+	r.vals = append(r.vals, v)
+
+	for r.idx[r.head] <= r.seq-r.period {
+		r.head++
+	}
+	r.seq++
+
+	// 已出队的部分过半时整理底层数组
+	if r.head > 0 && r.head*2 >= len(r.vals) {
+		r.idx = append(r.idx[:0], r.idx[r.head:]...)
+		r.vals = append(r.vals[:0], r.vals[r.head:]...)
+		r.head = 0
+	}
+}
+
+// value 当前窗口（不足 period 时为已有部分）的极值
+func (r *rollingExtreme) value() float64 {
+	return r.vals[r.head]
+}
+
+func (r *rollingExtreme) full() bool {
+	return r.seq >= r.period
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// testSeries 可复现的随机序列：价格按 0.01 取整，制造大量相等值与成段的 0
+func testSeries(n int, seed int64) []float64 {
+	r := rand.New(rand.NewSource(seed))
+	out := make([]float64, n)
+	for i := range out {
+		switch {
+		case i%97 >= 80:
+			out[i] = 0
+		default:
+			out[i] = math.Round((10+r.NormFloat64())*100) / 100
+		}
+	}
+	return out
+}
+
+// naiveWindow 第 i 根（含）往前最多 period 个值
+func naiveWindow(values []float64, i, period int) []float64 {
+	return values[max(0, i-period+1) : i+1]
+}
+
+func naiveExtreme(window []float64, isMax bool) float64 {
+	v := window[0]
+	for _, x := range window[1:] {
+		if (isMax && x > v) || (!isMax && x < v) {
+			v = x
+		}
+	}
+	return v
+}
+
+func naiveMeanVar(window []float64) (mean, variance float64) {
+	for _, x := range window {
+		mean += x
+	}
+	mean /= float64(len(window))
+	for _, x := range window {
+		variance += (x - mean) * (x - mean)
+	}
+	return mean, variance / float64(len(window))
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
+
+func TestRollingExtreme(t *testing.T) {
+	values := testSeries(2000, 1)
+	for _, period := range []int{1, 2, 5, 20, 100} {
+		highest, lowest := newRollingMax(period), newRollingMin(period)
+		for i, v := range values {
+			highest.push(v)
+			lowest.push(v)
+			window := naiveWindow(values, i, period)
+			if got, want := highest.value(), naiveExtreme(window, true); got != want {
+				t.Fatalf("period %d bar %d: max %v, want %v", period, i, got, want)
+			}
+			if got, want := lowest.value(), naiveExtreme(window, false); got != want {
+				t.Fatalf("period %d bar %d: min %v, want %v", period, i, got, want)
+			}
+			if want := i >= period-1; highest.full() != want {
+				t.Fatalf("period %d bar %d: full %v, want %v", period, i, highest.full(), want)
+			}
+		}
+	}
+}
+
+func TestRollingSum(t *testing.T) {
+	values := testSeries(2000, 2)
+	for _, period := range []int{1, 3, 20, 100} {
+		sum := newRollingSum(period)
+		for i, v := range values {
+			sum.push(v)
+			if want := i >= period-1; sum.full() != want {
+				t.Fatalf("period %d bar %d: full %v, want %v", period, i, sum.full(), want)
+			}
+
+			window := naiveWindow(values, i, period)
+			want, allZero := 0.0, true
+			for _, x := range window {
+				want += x
+				allZero = allZero && x == 0
+			}
+			if !closeTo(sum.sum, want) {
+				t.Fatalf("period %d bar %d: sum %v, want %v", period, i, sum.sum, want)
+			}
+			// 窗口全为 0 时必须精确为 0（CR 以此判断除数）
+			if allZero && sum.sum != 0 {
+				t.Fatalf("period %d bar %d: all-zero window sum %v, want exactly 0", period, i, sum.sum)
+			}
+		}
+	}
+}
+
+func TestRollingStats(t *testing.T) {
+	values := testSeries(2000, 3)
+	for _, period := range []int{1, 2, 20, 100} {
+		stats := newRollingStats(period)
+		for i, v := range values {
+			stats.push(v)
+			window := naiveWindow(values, i, period)
+			mean, variance := naiveMeanVar(window)
+			if !closeTo(stats.mean, mean) {
+				t.Fatalf("period %d bar %d: mean %v, want %v", period, i, stats.mean, mean)
+			}
+			if !closeTo(stats.variance(), variance) {
+				t.Fatalf("period %d bar %d: variance %v, want %v", period, i, stats.variance(), variance)
+			}
+			if stats.variance() < 0 {
+				t.Fatalf("period %d bar %d: negative variance %v", period, i, stats.variance())
+			}
+			// 窗口内全部相等时必须精确为 0，否则开方会把残差放大成非零带宽
+			if naiveExtreme(window, true) == naiveExtreme(window, false) && stats.variance() != 0 {
+				t.Fatalf("period %d bar %d: flat window variance %v, want exactly 0", period, i, stats.variance())
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	evaluate2 "wolf_street/evaluate"
+)
+
+// EvaluatorConfig 单个 Evaluator 的开关、权重与参数
+type EvaluatorConfig struct {
+	Name    string   `yaml:"name"`
+	Enabled *bool    `yaml:"enabled"` // 为空时视为启用
+	Weight  *float64 `yaml:"weight"`  // 得分倍数，为空时为 1；0 表示照常输出信号但不计分
+	Params  Params   `yaml:"params"`
+}
+
+func (c EvaluatorConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// EffectiveWeight 未设置权重时为 1
+func (c EvaluatorConfig) EffectiveWeight() float64 {
+	if c.Weight == nil {
+		return 1
+	}
+	return *c.Weight
+}
+
+// ScoringConfig ScoringEngine 按顺序启用的 Evaluator
+type ScoringConfig struct {
+	Evaluators []EvaluatorConfig `yaml:"evaluators"`
+}
+
+// defaultEvaluators 默认启用的 Evaluator，顺序即信号输出顺序
+// williams_r 不在默认列表中（避免改变既有股票的默认得分），需在评分配置中显式启用
+var defaultEvaluators = []string{
+	"rsi", "stoch_rsi", "cci", "kdj",
+	"bollinger", "ema", "macd", "sar", "atr", "vwap", "arbr", "cr", "ichimoku", "keltner", "td_sequential",
+	"volume", "mtf",
+}
+
+// percentileEvaluators 支持 percentile / percentile_window 参数的振荡指标
+var percentileEvaluators = []string{"rsi", "stoch_rsi", "cci", "kdj", "williams_r"}
+
+func DefaultScoringConfig() ScoringConfig {
+	cfg := ScoringConfig{}
+	for _, name := range defaultEvaluators {
+		cfg.Evaluators = append(cfg.Evaluators, EvaluatorConfig{Name: name})
+	}
+	return cfg
+}
+
+// LoadScoringConfig 读取 YAML 评分配置，未列出的 Evaluator 不启用
+func LoadScoringConfig(path string) (ScoringConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ScoringConfig{}, fmt.Errorf("read scoring config %s: %w", path, err)
+	}
+	var cfg ScoringConfig
+	if err = yaml.Unmarshal(data, &cfg); err != nil {
+		return ScoringConfig{}, fmt.Errorf("parse scoring config %s: %w", path, err)
+	}
+	if err = cfg.Validate(); err != nil {
+		return ScoringConfig{}, fmt.Errorf("scoring config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate 检查 Evaluator 名称均已注册且不重复
+func (c ScoringConfig) Validate() error {
+	seen := map[string]bool{}
+	for _, ec := range c.Evaluators {
+		if _, ok := evaluatorRegistry[ec.Name]; !ok {
+			return fmt.Errorf("unknown evaluator %q", ec.Name)
+		}
+		if seen[ec.Name] {
+			return fmt.Errorf("duplicate evaluator %q", ec.Name)
+		}
+		seen[ec.Name] = true
+	}
+	return nil
+}
+
+// Find 按名称查找，不存在时返回 nil
+func (c *ScoringConfig) Find(name string) *EvaluatorConfig {
+	for i := range c.Evaluators {
+		if c.Evaluators[i].Name == name {
+			return &c.Evaluators[i]
+		}
+	}
+	return nil
+}
+
+// SetParam 设置已配置 Evaluator 的参数，未配置时忽略
+func (c *ScoringConfig) SetParam(name, key string, v float64) {
+	ec := c.Find(name)
+	if ec == nil {
+		return
+	}
+	if ec.Params == nil {
+		ec.Params = Params{}
+	}
+	ec.Params[key] = v
+}
+
+// UsePercentileBands 全部振荡指标改用近 window 根自身历史的分位数阈值
+func (c *ScoringConfig) UsePercentileBands(window int) {
+	for _, name := range percentileEvaluators {
+		c.SetParam(name, "percentile", 1)
+		c.SetParam(name, "percentile_window", float64(window))
+	}
+}
+
+// Build 在 data 上构造启用的 Evaluator，权重不为 1 时包装为加权 Evaluator；progress 可为 nil
+func (c ScoringConfig) Build(data *IndicatorSet, progress ProgressFunc) ([]evaluate2.Evaluator, error) {
+	var enabled []EvaluatorConfig
+	for _, ec := range c.Evaluators {
+		if ec.IsEnabled() {
+			enabled = append(enabled, ec)
+		}
+	}
+
+	var evaluators []evaluate2.Evaluator
+	for i, ec := range enabled {
+		ev, err := NewEvaluator(ec.Name, data, ec.Params)
+		if err != nil {
+			return nil, err
+		}
+		if w := ec.EffectiveWeight(); w != 1 {
+			ev = weightedEvaluator{Evaluator: ev, weight: w}
+		}
+		evaluators = append(evaluators, ev)
+		progress.report(ec.Name, i+1, len(enabled))
+	}
+	return evaluators, nil
+}
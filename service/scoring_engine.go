@@ -1,341 +1,86 @@
 package service
 
 import (
-	"fmt"
+	"errors"
 	"go.uber.org/zap"
-	"log"
+	"math"
 	_const "wolf_street/const"
 	evaluate2 "wolf_street/evaluate"
 	"wolf_street/pkginit"
 )
 
 type ScoringEngine struct {
-	KC           KC
-	TDSequential []int
-	RSI          []float64
-	StochRSI     []float64
-	CCI          []float64
-	KDJ          []KDJValue
-	SAR          []float64
-	Bollinger    BollingerBand
-	MACD         MACD
-	EMAShort     []float64
-	EMALong      []float64
-	ATR          []float64
-	VWAP         []float64
-	ArBr         ARBR
-	CR           []float64
-	Ichimoku     []float64
-	Prices       []float64
-	Candles      []Candle
+	Candles    []Candle
+	Prices     []float64
+	Data       *IndicatorSet         // 各 Evaluator 共享的指标缓存
+	Evaluators []evaluate2.Evaluator // 按顺序评分，Warmup 之前的 bar 跳过
+	Tracker    *SignalTracker        // 信号冷却 / 去抖，nil 表示每根 bar 独立计分
 }
 
+// Score 第 index 根 bar 的总分与计分信号；设置了 Tracker 时冷却期内的信号不计分
+// 权重可为小数，总分按各信号得分之和四舍五入（math.Round，0.5 远离 0）取整，例如 0.5 → 1、-1.5 → -2
 func (se *ScoringEngine) Score(index int) (score int, signals []string) {
-	score = 0
-	signals = []string{}
-	price := se.Prices[index]
-
-	/* RSI */
-	res, err := evaluate2.EvaluateRSISignals(se.RSI, index)
-	if err != nil {
-		pkginit.Logger.Error("EvaluateRSISignals failed", zap.Error(err))
-		return
-	}
-	signals = append(signals, res.Signals...)
-	score += score
-
-	/* StochRSI */
-	cfg := evaluate2.DefaultStochRSIConfig()
-	// 可按需微调
-	cfg.SlopeLookback = 4
-	cfg.MinRiseBars = 3
-	cfg.CrossoverHysteresis = 2
-	cfg.EnableMTF = true
-	if index > cfg.SlopeLookback {
-		res, err = evaluate2.EvaluateStochRSISignals(se.StochRSI, index, cfg)
-		if err != nil {
-			pkginit.Logger.Error("EvaluateStochRSISignals failed", zap.Error(err))
-			//return
-		}
-		/*	fmt.Println("Score:", res.Score)
-			fmt.Println("Signals:", res.Signals)
-			fmt.Println("Components:", res.Components) // 看到每一项的贡献，方便调参
-		*/
-		signals = append(signals, res.Signals...)
-		score += int(res.Score)
-	}
-
-	// CCI
-	if se.CCI[index] > 100 {
-		score += 1
-		signals = append(signals, "CCI强多头")
-	} else if se.CCI[index] < -100 {
-		score -= 1
-		signals = append(signals, "CCI强空头")
-	}
-
-	/* KDJ */
-	//if se.KDJ[index].J > 80 {
-	//	score -= 1
-	//	signals = append(signals, "KDJ超买")
-	//} else if se.KDJ[index].J < 20 {
-	//	score += 1
-	//	signals = append(signals, "KDJ超卖")
-	//}
-	kdj := kdjAdapter{ref: se.KDJ}
-	pr := priceAdapter{ref: se.Prices}
-	evaluateScore, evaluateSignals, err := evaluate2.EvaluateKDJSignals(kdj, pr, index)
-	if err != nil {
-		log.Println("KDJ evaluation failed:", err)
+	var hits []SignalHit
+	if se.Tracker != nil {
+		hits = se.trackedHits(index)
 	} else {
-		score += evaluateScore
-		signals = append(signals, evaluateSignals...)
-		fmt.Println("KDJ score:", score)
-		fmt.Println("Signals:", signals)
-	}
-
-	// Bollinger Bands
-	if price < se.Bollinger.LowerBand[index] {
-		score += 1
-		signals = append(signals, "布林带下轨突破")
-	} else if price > se.Bollinger.UpperBand[index] {
-		score -= 1
-		signals = append(signals, "布林带上轨突破")
+		hits = se.Hits(index)
 	}
 
-	// EMA
-	if se.EMAShort[index] > se.EMALong[index] {
-		score += 1
-		signals = append(signals, "EMA金叉")
-	} else if se.EMAShort[index] < se.EMALong[index] {
-		score -= 1
-		signals = append(signals, "EMA死叉")
+	total := 0.0
+	signals = []string{}
+	for _, h := range hits {
+		total += h.Score
+		signals = append(signals, h.Name)
 	}
+	return int(math.Round(total)), signals
+}
 
-	// MACD
-	if index > 0 {
-		if se.MACD.MACDLine[index-1] < se.MACD.SignalLine[index-1] && se.MACD.MACDLine[index] > se.MACD.SignalLine[index] {
-			score += 1
-			signals = append(signals, "MACD金叉")
-		} else if se.MACD.MACDLine[index-1] > se.MACD.SignalLine[index-1] && se.MACD.MACDLine[index] < se.MACD.SignalLine[index] {
-			score -= 1
-			signals = append(signals, "MACD死叉")
+// trackedHits 按顺序把 Tracker 推进到 index，返回当根实际触发的信号
+func (se *ScoringEngine) trackedHits(index int) []SignalHit {
+	for i := se.Tracker.Len(); i <= index; i++ {
+		if _, err := se.Tracker.Update(i, se.Hits(i)); err != nil {
+			pkginit.Logger.Error("SignalTracker update failed", zap.Int("index", i), zap.Error(err))
+			return nil
 		}
 	}
 
-	// SAR 反转信号
-	if price > se.SAR[index] {
-		score += 1
-		signals = append(signals, "SAR支撑")
-	} else if price < se.SAR[index] {
-		score -= 1
-		signals = append(signals, "SAR压制")
-	}
-
-	// ATR 辅助
-	if index > 0 && se.ATR[index] > se.ATR[index-1] {
-		signals = append(signals, "ATR上升")
-	} else if index > 0 && se.ATR[index] < se.ATR[index-1] {
-		signals = append(signals, "ATR下降")
-	}
-
-	// VWAP 信号
-	if price > se.VWAP[index] {
-		score += 1
-		signals = append(signals, "价格上穿VWAP（强势）")
-	} else if price < se.VWAP[index] {
-		score -= 1
-		signals = append(signals, "价格下穿VWAP（弱势）")
-	}
-
-	// ARBR 信号
-	if se.ArBr.AR[index] > 120 && se.ArBr.BR[index] > 120 {
-		score += 1
-		signals = append(signals, "ARBR极强多头")
-	} else if se.ArBr.AR[index] < 80 && se.ArBr.BR[index] < 80 {
-		score -= 1
-		signals = append(signals, "ARBR极弱空头")
-	}
-
-	// CR 信号
-	if se.CR[index] > 150 {
-		score += 1
-		signals = append(signals, "CR强多头确认")
-	} else if se.CR[index] < 100 {
-		score -= 1
-		signals = append(signals, "CR偏空头确认")
-	}
-
-	// Ichimoku 基准线信号
-	if price > se.Ichimoku[index] {
-		score += 1
-		signals = append(signals, "价格上穿一目均衡表基准线（偏多）")
-	} else if price < se.Ichimoku[index] {
-		score -= 1
-		signals = append(signals, "价格下穿一目均衡表基准线（偏空）")
-	}
-
-	// Keltner Channel (KC)
-	if price > se.KC.UpperBand[index] {
-		score += 1
-		signals = append(signals, "价格突破Keltner上轨（趋势强势）")
-	} else if price < se.KC.LowerBand[index] {
-		score -= 1
-		signals = append(signals, "价格跌破Keltner下轨（弱势）")
-	}
-
-	// TD Sequential
-	if se.TDSequential[index] == 9 {
-		score -= 1
-		signals = append(signals, "TD9顶部反转警告")
-	} else if se.TDSequential[index] == -9 {
-		score += 1
-		signals = append(signals, "TD9底部反转警告")
+	var hits []SignalHit
+	for _, st := range se.Tracker.Status(index) {
+		if st.Fired {
+			hits = append(hits, st.SignalHit)
+		}
 	}
-
-	return
+	return hits
 }
 
-func (se *ScoringEngine) ScoreBak(index int) (score int, signals []string) {
-	score = 0
-	signals = []string{}
-	price := se.Prices[index]
-
-	// RSI
-	if se.RSI[index] < 30 {
-		score += 1
-		signals = append(signals, "RSI超卖")
-	} else if se.RSI[index] > 70 {
-		score -= 1
-		signals = append(signals, "RSI超买")
-	}
-
-	// StochRSI
-	if se.StochRSI[index] < 0.2 {
-		score += 1
-		signals = append(signals, "StochRSI超卖")
-	} else if se.StochRSI[index] > 0.8 {
-		score -= 1
-		signals = append(signals, "StochRSI超买")
-	}
-
-	// CCI
-	if se.CCI[index] > 100 {
-		score += 1
-		signals = append(signals, "CCI强多头")
-	} else if se.CCI[index] < -100 {
-		score -= 1
-		signals = append(signals, "CCI强空头")
-	}
-
-	// KDJ
-	if se.KDJ[index].J > 80 {
-		score -= 1
-		signals = append(signals, "KDJ超买")
-	} else if se.KDJ[index].J < 20 {
-		score += 1
-		signals = append(signals, "KDJ超卖")
-	}
-
-	// Bollinger Bands
-	if price < se.Bollinger.LowerBand[index] {
-		score += 1
-		signals = append(signals, "布林带下轨突破")
-	} else if price > se.Bollinger.UpperBand[index] {
-		score -= 1
-		signals = append(signals, "布林带上轨突破")
-	}
-
-	// EMA
-	if se.EMAShort[index] > se.EMALong[index] {
-		score += 1
-		signals = append(signals, "EMA金叉")
-	} else if se.EMAShort[index] < se.EMALong[index] {
-		score -= 1
-		signals = append(signals, "EMA死叉")
+// SignalStatus 第 index 根 bar 的信号状态（含持续 bar 数与是否触发），未设置 Tracker 时返回 nil
+func (se *ScoringEngine) SignalStatus(index int) []SignalStatus {
+	if se.Tracker == nil {
+		return nil
 	}
+	se.trackedHits(index)
+	return se.Tracker.Status(index)
+}
 
-	// MACD
-	if index > 0 {
-		if se.MACD.MACDLine[index-1] < se.MACD.SignalLine[index-1] && se.MACD.MACDLine[index] > se.MACD.SignalLine[index] {
-			score += 1
-			signals = append(signals, "MACD金叉")
-		} else if se.MACD.MACDLine[index-1] > se.MACD.SignalLine[index-1] && se.MACD.MACDLine[index] < se.MACD.SignalLine[index] {
-			score -= 1
-			signals = append(signals, "MACD死叉")
+// Hits 第 index 根 bar 命中的全部信号及各自得分（未经冷却过滤）；预热期内或返回 ErrNotReady 的指标不参与
+func (se *ScoringEngine) Hits(index int) (hits []SignalHit) {
+	for _, ev := range se.Evaluators {
+		if index < ev.Warmup() {
+			continue
+		}
+		res, err := ev.Evaluate(index)
+		if errors.Is(err, evaluate2.ErrNotReady) {
+			continue
+		}
+		if err != nil {
+			pkginit.Logger.Debug("evaluator skipped", zap.String("evaluator", ev.Name()), zap.Int("index", index), zap.Error(err))
+			continue
+		}
+		for _, s := range res.Signals {
+			hits = append(hits, SignalHit{Name: s, Score: res.SignalScores[s]})
 		}
 	}
-
-	// SAR 反转信号
-	if price > se.SAR[index] {
-		score += 1
-		signals = append(signals, "SAR支撑")
-	} else if price < se.SAR[index] {
-		score -= 1
-		signals = append(signals, "SAR压制")
-	}
-
-	// ATR 辅助
-	if index > 0 && se.ATR[index] > se.ATR[index-1] {
-		signals = append(signals, "ATR上升")
-	} else if index > 0 && se.ATR[index] < se.ATR[index-1] {
-		signals = append(signals, "ATR下降")
-	}
-
-	// VWAP 信号
-	if price > se.VWAP[index] {
-		score += 1
-		signals = append(signals, "价格上穿VWAP（强势）")
-	} else if price < se.VWAP[index] {
-		score -= 1
-		signals = append(signals, "价格下穿VWAP（弱势）")
-	}
-
-	// ARBR 信号
-	if se.ArBr.AR[index] > 120 && se.ArBr.BR[index] > 120 {
-		score += 1
-		signals = append(signals, "ARBR极强多头")
-	} else if se.ArBr.AR[index] < 80 && se.ArBr.BR[index] < 80 {
-		score -= 1
-		signals = append(signals, "ARBR极弱空头")
-	}
-
-	// CR 信号
-	if se.CR[index] > 150 {
-		score += 1
-		signals = append(signals, "CR强多头确认")
-	} else if se.CR[index] < 100 {
-		score -= 1
-		signals = append(signals, "CR偏空头确认")
-	}
-
-	// Ichimoku 基准线信号
-	if price > se.Ichimoku[index] {
-		score += 1
-		signals = append(signals, "价格上穿一目均衡表基准线（偏多）")
-	} else if price < se.Ichimoku[index] {
-		score -= 1
-		signals = append(signals, "价格下穿一目均衡表基准线（偏空）")
-	}
-
-	// Keltner Channel (KC)
-	if price > se.KC.UpperBand[index] {
-		score += 1
-		signals = append(signals, "价格突破Keltner上轨（趋势强势）")
-	} else if price < se.KC.LowerBand[index] {
-		score -= 1
-		signals = append(signals, "价格跌破Keltner下轨（弱势）")
-	}
-
-	// TD Sequential
-	if se.TDSequential[index] == 9 {
-		score -= 1
-		signals = append(signals, "TD9顶部反转警告")
-	} else if se.TDSequential[index] == -9 {
-		score += 1
-		signals = append(signals, "TD9底部反转警告")
-	}
-
 	return
 }
 
@@ -360,10 +105,3 @@ type priceAdapter struct{ ref []float64 }
 
 func (a priceAdapter) Len() int         { return len(a.ref) }
 func (a priceAdapter) At(i int) float64 { return a.ref[i] }
-
-// Example call from your engine
-func (se *ScoringEngine) EvalKDJAt(index int) (int, []string, error) {
-	kdj := kdjAdapter{ref: se.KDJ}
-	pr := priceAdapter{ref: se.Prices}
-	return evaluate2.EvaluateKDJSignals(kdj, pr, index)
-}
@@ -1,77 +1,33 @@
 package service
 
-import (
-	"fmt"
-	"time"
-)
-
-func StrategyScoringEngine(candles []Candle) error {
-	bar := NewTaggedProgressBar(len(candles), len(candles))
-
-	var open, highs, lows, closes []float64
-	for _, candle := range candles {
-		open = append(open, candle.Open)
-		highs = append(highs, candle.High)
-		lows = append(lows, candle.Low)
-		closes = append(closes, candle.Close)
-
-		bar.Add(1)
-		time.Sleep(10 * time.Millisecond)
-		bar.Finish()
+// NewScoringEngine 按默认评分配置计算全部指标并组装 ScoringEngine
+func NewScoringEngine(candles []Candle) *ScoringEngine {
+	se, err := NewScoringEngineWithConfig(candles, DefaultScoringConfig())
+	if err != nil {
+		// 默认配置只引用内置 Evaluator，不会失败
+		panic(err)
 	}
-	prices := closes
-
-	rsi := CalculateRSI(prices, 14)
-	stochRsi := CalculateStochRSI(prices, 14)
-	cci := CalculateCCI(highs, lows, closes, 20)
-	kdj := CalculateKDJ(highs, lows, closes, 9)
-	sar := CalculateSAR(highs, lows, 0.02, 0.2)
-	bollinger := CalculateBollinger(prices, 20)
-	emaShort := CalculateEMA(prices, 12)
-	emaLong := CalculateEMA(prices, 26)
-	macd := CalculateMACD(prices)
-	atr := CalculateATR(highs, lows, closes, 14)
-	vwap := CalculateVWAP(candles)
-	arbr := CalculateARBR(candles)
-	cr := CalculateCR(candles, 26)
-	ichimoku := CalculateIchimokuBaseLine(highs, lows, 26)
-	kcband := CalculateKeltnerChannel(highs, lows, closes, 20)
-	tdSeq := CalculateTDSequential(closes)
+	return se
+}
 
-	se := ScoringEngine{
-		RSI:          rsi,
-		StochRSI:     stochRsi,
-		CCI:          cci,
-		KDJ:          kdj,
-		SAR:          sar,
-		Bollinger:    bollinger,
-		MACD:         macd,
-		EMAShort:     emaShort,
-		EMALong:      emaLong,
-		ATR:          atr,
-		VWAP:         vwap,
-		Prices:       prices, // 必须加这个
-		Candles:      candles,
-		ArBr:         arbr,
-		CR:           cr,
-		Ichimoku:     ichimoku,
-		KC:           kcband,
-		TDSequential: tdSeq,
-		// 省略其他指标初始化
-	}
+// NewScoringEngineWithConfig 按 cfg 启用 / 参数化 Evaluator 组装 ScoringEngine
+func NewScoringEngineWithConfig(candles []Candle, cfg ScoringConfig) (*ScoringEngine, error) {
+	return NewScoringEngineWithProgress(candles, cfg, nil)
+}
 
-	fmt.Println(" \n\n ======= Scoring Engine Result: ======= \n ")
-	for i := 0; i < len(prices); i++ {
-		score, signals := se.Score(i)
-		if i < 10 {
-			tradeSignal := GenerateTradeSignal(score)
-			fmt.Printf(" (%d.) %s = $ %f | Score: %d, tradeSignal: %s , Signals: %v\n", i+1, candles[i].Date, candles[i].Close, score, tradeSignal, signals)
-		}
+// NewScoringEngineWithProgress 同 NewScoringEngineWithConfig，每构造完一个 Evaluator（含指标计算）回调一次 progress，可为 nil
+func NewScoringEngineWithProgress(candles []Candle, cfg ScoringConfig, progress ProgressFunc) (*ScoringEngine, error) {
+	data := NewIndicatorSet(candles)
+	evaluators, err := cfg.Build(data, progress)
+	if err != nil {
+		return nil, err
 	}
 
-	/* Trading */
-	trades := BacktestTrades(&se, 2)
-	PrintTradeStats(trades)
-
-	return nil
+	return &ScoringEngine{
+		Candles:    candles,
+		Prices:     data.Closes,
+		Data:       data,
+		Evaluators: evaluators,
+		Tracker:    NewDefaultSignalTracker(),
+	}, nil
 }
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"errors"
+	"strings"
+)
+
+// SignalHit 单个命中信号及其得分
+type SignalHit struct {
+	Name  string
+	Score float64
+}
+
+type TriggerMode int
+
+const (
+	LevelTriggered TriggerMode = iota // 条件成立的每根 bar 都可触发（受冷却期限制）
+	EdgeTriggered                     // 只在条件由不成立变为成立的那根 bar 触发
+)
+
+func (m TriggerMode) String() string {
+	if m == EdgeTriggered {
+		return "edge"
+	}
+	return "level"
+}
+
+// SignalRule 信号的触发方式与冷却期：触发后 Cooldown 根 bar 内不再计分
+type SignalRule struct {
+	Mode     TriggerMode
+	Cooldown int
+}
+
+// SignalStatus 某根 bar 上一个信号的状态
+type SignalStatus struct {
+	SignalHit
+	ActiveBars int  // 所属规则连续成立的 bar 数（含当前）
+	Fired      bool // 是否计入当根得分
+}
+
+type signalState struct {
+	lastSeen   int
+	lastFired  int
+	activeBars int
+}
+
+/*
+SignalTracker 有状态的信号去抖：同一超卖/超买条件连续多根成立时避免重复计分
+规则按信号名前缀匹配（最长前缀优先），例如 "StochRSI" 对全部 StochRSI 信号生效
+冷却与持续 bar 数按匹配到的规则记录，而不是具体信号名：StochRSI 由严重超卖转为轻度超卖仍算同一段行情，不会重新计分；
+同一根 bar 上同一规则下的多个信号一起触发或一起处于冷却；未匹配任何前缀的信号按各自名称单独记录
+必须按 bar 顺序从 0 开始逐根 Update，已处理过的 bar 通过 Status 读取
+*/
+type SignalTracker struct {
+	Default SignalRule
+	Rules   map[string]SignalRule
+
+	states  map[string]*signalState
+	history [][]SignalStatus
+}
+
+func NewSignalTracker(def SignalRule) *SignalTracker {
+	return &SignalTracker{Default: def, Rules: map[string]SignalRule{}, states: map[string]*signalState{}}
+}
+
+// NewDefaultSignalTracker ScoringEngine 默认规则：StochRSI 信号按 StochRSIConfig.CooldownBars 冷却，其余信号不限
+func NewDefaultSignalTracker() *SignalTracker {
+	t := NewSignalTracker(SignalRule{Mode: LevelTriggered})
+	t.SetRule("StochRSI", SignalRule{Mode: LevelTriggered, Cooldown: stochRSIConfig().CooldownBars})
+	return t
+}
+
+// SetRule 为名称以 prefix 开头的信号设置规则
+func (t *SignalTracker) SetRule(prefix string, rule SignalRule) {
+	t.Rules[prefix] = rule
+}
+
+func (t *SignalTracker) Rule(name string) SignalRule {
+	rule, _ := t.match(name)
+	return rule
+}
+
+// match 信号适用的规则及其状态键（匹配到的前缀，未匹配时为信号名本身）
+func (t *SignalTracker) match(name string) (SignalRule, string) {
+	rule, key, best := t.Default, name, -1
+	for prefix, r := range t.Rules {
+		if strings.HasPrefix(name, prefix) && len(prefix) > best {
+			rule, key, best = r, prefix, len(prefix)
+		}
+	}
+	return rule, key
+}
+
+// Len 已处理的 bar 数
+func (t *SignalTracker) Len() int {
+	return len(t.history)
+}
+
+// Update 处理第 index 根 bar 的全部命中信号，index 必须等于 Len()；已处理过的 bar 直接返回历史结果
+func (t *SignalTracker) Update(index int, hits []SignalHit) ([]SignalStatus, error) {
+	if index < len(t.history) {
+		return t.history[index], nil
+	}
+	if index > len(t.history) {
+		return nil, errors.New("signal tracker: bars must be updated in order")
+	}
+
+	statuses := make([]SignalStatus, 0, len(hits))
+	for _, h := range hits {
+		rule, key := t.match(h.Name)
+		st, ok := t.states[key]
+		if !ok {
+			st = &signalState{lastSeen: -2, lastFired: -1}
+			t.states[key] = st
+		}
+
+		// 同一规则在本根 bar 已判定过，沿用同一结果
+		if st.lastSeen == index {
+			statuses = append(statuses, SignalStatus{SignalHit: h, ActiveBars: st.activeBars, Fired: st.lastFired == index})
+			continue
+		}
+
+		if st.lastSeen == index-1 {
+			st.activeBars++
+		} else {
+			st.activeBars = 1
+		}
+		st.lastSeen = index
+
+		fired := st.lastFired < 0 || index-st.lastFired > rule.Cooldown
+		if rule.Mode == EdgeTriggered && st.activeBars > 1 {
+			fired = false
+		}
+		if fired {
+			st.lastFired = index
+		}
+		statuses = append(statuses, SignalStatus{SignalHit: h, ActiveBars: st.activeBars, Fired: fired})
+	}
+
+	t.history = append(t.history, statuses)
+	return statuses, nil
+}
+
+// Status 第 index 根 bar 的信号状态，未处理时返回 nil
+func (t *SignalTracker) Status(index int) []SignalStatus {
+	if index < 0 || index >= len(t.history) {
+		return nil
+	}
+	return t.history[index]
+}
+
+// ActiveBars 信号所属规则在最近处理的 bar 上连续成立的 bar 数，未成立时为 0
+func (t *SignalTracker) ActiveBars(name string) int {
+	_, key := t.match(name)
+	st, ok := t.states[key]
+	if !ok || st.lastSeen != len(t.history)-1 {
+		return 0
+	}
+	return st.activeBars
+}
+
+func (t *SignalTracker) Reset() {
+	t.states = map[string]*signalState{}
+	t.history = nil
+}
@@ -1,11 +1,16 @@
 package service
 
+import "time"
+
 type Candle struct {
-	Date  string
-	Open  float64
-	High  float64
-	Low   float64
-	Close float64
+	Date     string    // YYYY-MM-DD
+	Time     time.Time // 解析后的日期
+	Open     float64
+	High     float64
+	Low      float64
+	Close    float64
+	AdjClose float64 // 复权收盘价，数据源没有时等于 Close
+	Volume   float64
 }
 
 type Trading struct {
@@ -28,7 +33,9 @@ type Trade struct {
 	Date   string
 	Signal string
 	Price  float64
-	PnL    float64
+	Qty    float64
+	Cost   float64 // 本笔成交费用
+	PnL    float64 // 平仓时的净盈亏（已扣开平仓费用）
 }
 
 /* Indicator */
@@ -56,8 +63,48 @@ type MACD struct {
 	Histogram  []float64
 }
 
+// StochRSIKD StochRSI 平滑后的 %K / %D，取值 0~1
+type StochRSIKD struct {
+	K []float64 // 原始 StochRSI 的 SMA(smoothK)
+	D []float64 // K 的 SMA(smoothD)
+}
+
+// BollingerValue / MACDValue / ARBRValue 单根 bar 的指标值，供流式指标 Update 返回
+
+type BollingerValue struct {
+	Lower float64
+	Mid   float64
+	Upper float64
+}
+
+type MACDValue struct {
+	MACD      float64
+	Signal    float64
+	Histogram float64
+}
+
+type ARBRValue struct {
+	AR float64
+	BR float64
+}
+
 type KC struct {
 	UpperBand  []float64
 	MiddleBand []float64
 	LowerBand  []float64
 }
+
+// Ichimoku 一目均衡表，下标与 K 线对齐
+// SenkouA / SenkouB 已向前平移 Displacement 根：第 i 个值即第 i 根 bar 处的云层，
+// 长度为 len(K 线)+Displacement，末尾 Displacement 个值是尚未到来的未来云层；
+// Chikou 向后平移：第 i 个值为第 i+Displacement 根的收盘价，最后 Displacement 个为 NaN
+// 注意：Chikou[i] 是第 i 根之后的未来收盘价，只能用于画图，回测 / 评分中在第 i 根读取 Chikou[i] 属于未来函数；
+// 需要迟行线确认时应比较 closes[i] 与 closes[i-Displacement]（即 Chikou[i-Displacement] 与当时的价格）
+type Ichimoku struct {
+	Tenkan       []float64 // 转换线
+	Kijun        []float64 // 基准线
+	SenkouA      []float64 // 先行带 A
+	SenkouB      []float64 // 先行带 B
+	Chikou       []float64 // 迟行线（含未来数据！Chikou[i] = 第 i+Displacement 根收盘价，评分勿在第 i 根使用）
+	Displacement int
+}
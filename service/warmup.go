@@ -0,0 +1,61 @@
+package service
+
+import "math"
+
+/*
+指标预热期：Calculate* 返回序列中前 Warmup 个值无定义，统一为 NaN（流式 *Stream 同样返回 NaN）
+NaN 参与比较结果均为 false，评分规则不会在预热期内误触发；ScoringEngine 按 Evaluator.Warmup 跳过未就绪的指标
+*/
+
+func RSIWarmup(period int) int       { return period }
+func EMAWarmup(period int) int       { return period - 1 }
+func ATRWarmup(period int) int       { return period }
+func BollingerWarmup(period int) int { return period - 1 }
+func KDJWarmup(period int) int       { return period - 1 }
+func CCIWarmup(period int) int       { return period - 1 }
+func WilliamsRWarmup(period int) int { return period - 1 }
+func CRWarmup(period int) int        { return period }
+func IchimokuWarmup(period int) int  { return period - 1 }
+func StochRSIWarmup(period int) int  { return 2 * period }
+
+// SARWarmup 第 1 根没有 SAR；第 2 根由前两根的高低点起算（见 CalculateSAR），此后即为有效值，不再从 0 爬升
+func SARWarmup() int { return 1 }
+
+func ARBRWarmup() int { return 1 }
+
+// MACDWarmup 信号线就绪的位置（EMA26 预热 25 根 + 信号线 EMA9 预热 8 根），MACD 线本身在第 25 根起有值
+func MACDWarmup() int { return EMAWarmup(26) + EMAWarmup(9) }
+
+// KCWarmup 中轨 EMA 与 ATR 中较长的预热期
+func KCWarmup(period int) int { return max(EMAWarmup(period), ATRWarmup(period)) }
+
+// StochRSIKDWarmup %D 就绪的位置；%K 在 StochRSIWarmup(period)+smoothK-1 起有值
+func StochRSIKDWarmup(period, smoothK, smoothD int) int {
+	return StochRSIWarmup(period) + max(smoothK, 1) - 1 + max(smoothD, 1) - 1
+}
+
+// TDSequentialWarmup TD 计数从第 5 根开始；序列为 int，未计数时为 0
+func TDSequentialWarmup() int { return 4 }
+
+func MFIWarmup(period int) int         { return period }
+func CMFWarmup(period int) int         { return period - 1 }
+func VolumeSMAWarmup(period int) int   { return period - 1 }
+func VolumeSpikeWarmup(period int) int { return period }
+
+// FirstValid 第一个非 NaN 值的下标，全部无效时返回 len(series)
+func FirstValid(series []float64) int {
+	for i, v := range series {
+		if !math.IsNaN(v) {
+			return i
+		}
+	}
+	return len(series)
+}
+
+func nanSeries(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
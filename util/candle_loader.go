@@ -0,0 +1,316 @@
+package util
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"wolf_street/pkginit"
+	"wolf_street/service"
+)
+
+// CandleColumns 各列对应的表头名称，为空时按常见别名自动识别
+type CandleColumns struct {
+	Date     string
+	Open     string
+	High     string
+	Low      string
+	Close    string
+	AdjClose string
+	Volume   string
+}
+
+type CandleLoaderConfig struct {
+	Columns     CandleColumns
+	DateLayouts []string       // 依次尝试的日期格式
+	Location    *time.Location // 日期所属时区
+}
+
+// Bursa 交易时间为 UTC+8
+var bursaLocation = time.FixedZone("MYT", 8*60*60)
+
+func DefaultCandleLoaderConfig() CandleLoaderConfig {
+	return CandleLoaderConfig{
+		DateLayouts: []string{"2006-01-02", "2006/01/02", "02/01/2006", "02-01-2006", "20060102", "2006-01-02 15:04:05", time.RFC3339},
+		Location:    bursaLocation,
+	}
+}
+
+// columnAliases 自动识别表头时使用的别名（小写）
+var columnAliases = map[string][]string{
+	"date":      {"date", "datetime", "time", "timestamp", "日期"},
+	"open":      {"open", "开盘", "开盘价"},
+	"high":      {"high", "最高", "最高价"},
+	"low":       {"low", "最低", "最低价"},
+	"close":     {"close", "price", "last", "收盘", "收盘价"},
+	"adj_close": {"adj close", "adj_close", "adjclose", "adjusted close", "复权收盘价"},
+	"volume":    {"volume", "vol", "成交量"},
+}
+
+// RowError 单行解析错误，Line 为文件中的行号（从 1 开始，含表头）
+type RowError struct {
+	Line   int
+	Column string
+	Value  string
+	Err    error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("line %d: %s=%q: %v", e.Line, e.Column, e.Value, e.Err)
+}
+
+// CandleParseError 解析时被跳过的全部行。返回此错误时，其余有效 K 线仍会一并返回
+type CandleParseError struct {
+	File string
+	Rows []RowError
+}
+
+func (e *CandleParseError) Error() string {
+	const show = 5
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d bad rows", e.File, len(e.Rows))
+	for i, r := range e.Rows {
+		if i == show {
+			fmt.Fprintf(&b, "; ... and %d more", len(e.Rows)-show)
+			break
+		}
+		b.WriteString("; ")
+		b.WriteString(r.Error())
+	}
+	return b.String()
+}
+
+// LoadCandleFileWithConfig 读取 K 线 CSV：按表头识别列、解析日期与成交量、按日期排序去重
+func LoadCandleFileWithConfig(filePath string, cfg CandleLoaderConfig) ([]service.Candle, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		// 判断是否为文件不存在错误
+		if errors.Is(err, os.ErrNotExist) {
+			pkginit.Logger.Warn("Candle data file not found", zap.String("filePath", filePath))
+			return nil, os.ErrNotExist
+		}
+
+		// 其他打开文件的错误
+		pkginit.Logger.Error("Failed to open candle data file", zap.String("filePath", filePath), zap.Error(err))
+		return nil, err
+	}
+	defer file.Close()
+
+	candles, err := ParseCandles(file, cfg)
+	var perr *CandleParseError
+	if errors.As(err, &perr) {
+		perr.File = filePath
+	}
+	return candles, err
+}
+
+// ParseCandles 解析 CSV 内容，第一行必须是表头
+func ParseCandles(r io.Reader, cfg CandleLoaderConfig) ([]service.Candle, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		pkginit.Logger.Error("Failed to read CSV records", zap.Error(err))
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty candle file")
+	}
+
+	cols, err := resolveColumns(records[0], cfg.Columns)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+
+	perr := &CandleParseError{}
+	var candles []service.Candle
+	for n, record := range records[1:] {
+		line := n + 2
+		c, rowErr := parseCandleRow(record, cols, cfg)
+		if rowErr != nil {
+			rowErr.Line = line
+			perr.Rows = append(perr.Rows, *rowErr)
+			continue
+		}
+		candles = append(candles, c)
+	}
+
+	candles = sortDedupe(candles)
+	if len(perr.Rows) > 0 {
+		return candles, perr
+	}
+	return candles, nil
+}
+
+// columnIndex 每种角色对应的列下标，-1 表示不存在
+type columnIndex map[string]int
+
+func resolveColumns(header []string, mapping CandleColumns) (columnIndex, error) {
+	normalized := make([]string, len(header))
+	for i, h := range header {
+		normalized[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+	}
+	find := func(names ...string) int {
+		for _, name := range names {
+			for i, h := range normalized {
+				if h == strings.ToLower(name) {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+
+	explicit := map[string]string{
+		"date": mapping.Date, "open": mapping.Open, "high": mapping.High, "low": mapping.Low,
+		"close": mapping.Close, "adj_close": mapping.AdjClose, "volume": mapping.Volume,
+	}
+
+	cols := columnIndex{}
+	for role, aliases := range columnAliases {
+		if name := explicit[role]; name != "" {
+			cols[role] = find(name)
+			if cols[role] < 0 {
+				return nil, fmt.Errorf("column %q for %s not found in header", name, role)
+			}
+			continue
+		}
+		cols[role] = find(aliases...)
+	}
+
+	if cols["date"] < 0 || cols["close"] < 0 {
+		// 无法识别表头时沿用旧格式：date, open, high, low, close
+		if len(header) < 5 {
+			return nil, fmt.Errorf("cannot identify date/close columns in header %v", header)
+		}
+		pkginit.Logger.Warn("Unrecognized candle header, falling back to positional columns", zap.Strings("header", header))
+		cols = columnIndex{"date": 0, "open": 1, "high": 2, "low": 3, "close": 4, "adj_close": -1, "volume": -1}
+	}
+	return cols, nil
+}
+
+func parseCandleRow(record []string, cols columnIndex, cfg CandleLoaderConfig) (service.Candle, *RowError) {
+	field := func(role string) string {
+		i := cols[role]
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	number := func(role string, required bool) (float64, *RowError) {
+		raw := field(role)
+		if cols[role] < 0 || (raw == "" && !required) {
+			return 0, nil
+		}
+		v, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", ""), 64)
+		if err != nil {
+			return 0, &RowError{Column: role, Value: raw, Err: err}
+		}
+		return v, nil
+	}
+
+	rawDate := field("date")
+	t, err := parseDate(rawDate, cfg.DateLayouts, cfg.Location)
+	if err != nil {
+		return service.Candle{}, &RowError{Column: "date", Value: rawDate, Err: err}
+	}
+
+	c := service.Candle{Time: t, Date: t.Format("2006-01-02")}
+	var rowErr *RowError
+	if c.Close, rowErr = number("close", true); rowErr != nil {
+		return c, rowErr
+	}
+	if c.Open, rowErr = number("open", true); rowErr != nil {
+		return c, rowErr
+	}
+	if c.High, rowErr = number("high", true); rowErr != nil {
+		return c, rowErr
+	}
+	if c.Low, rowErr = number("low", true); rowErr != nil {
+		return c, rowErr
+	}
+	if c.AdjClose, rowErr = number("adj_close", false); rowErr != nil {
+		return c, rowErr
+	}
+	if c.Volume, rowErr = number("volume", false); rowErr != nil {
+		return c, rowErr
+	}
+
+	// 只有收盘价的文件：OHLC 都用收盘价
+	if cols["open"] < 0 {
+		c.Open = c.Close
+	}
+	if cols["high"] < 0 {
+		c.High = maxFloat(c.Open, c.Close)
+	}
+	if cols["low"] < 0 {
+		c.Low = minFloat(c.Open, c.Close)
+	}
+	if c.AdjClose == 0 {
+		c.AdjClose = c.Close
+	}
+
+	if c.Close <= 0 || c.Open <= 0 || c.High <= 0 || c.Low <= 0 {
+		return c, &RowError{Column: "close", Value: field("close"), Err: errors.New("non-positive price")}
+	}
+	if c.High < c.Low {
+		return c, &RowError{Column: "high", Value: field("high"), Err: fmt.Errorf("high %.4f below low %.4f", c.High, c.Low)}
+	}
+	if c.Volume < 0 {
+		return c, &RowError{Column: "volume", Value: field("volume"), Err: errors.New("negative volume")}
+	}
+	return c, nil
+}
+
+func parseDate(s string, layouts []string, loc *time.Location) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, errors.New("empty date")
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date format")
+}
+
+// sortDedupe 按日期升序排序，同一天出现多次时保留文件中靠后的一行
+func sortDedupe(candles []service.Candle) []service.Candle {
+	sort.SliceStable(candles, func(i, j int) bool {
+		return candles[i].Time.Before(candles[j].Time)
+	})
+
+	out := candles[:0]
+	for _, c := range candles {
+		if n := len(out); n > 0 && out[n-1].Date == c.Date {
+			pkginit.Logger.Debug("Duplicate candle date, keeping last", zap.String("date", c.Date))
+			out[n-1] = c
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,159 @@
+package util
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"wolf_street/model"
+	"wolf_street/service"
+)
+
+/*
+LoadCorporateActions 读取公司行动 CSV，表头（顺序不限）：
+
+	ex_date,type,ratio,amount,price
+
+ratio 可写小数或 "a:b"：
+  - bonus / rights：每 b 股获 a 股新股，例如 "1:2" 为每 2 股送 1 股
+  - split / consolidation：b 股变为 a 股，例如 "2:1" 为 1 拆 2，"1:5" 为 5 合 1
+
+amount 为每股现金股息，price 为附加股认购价
+*/
+func LoadCorporateActions(path string) ([]service.CorporateAction, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read corporate actions %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	col := map[string]int{}
+	for i, h := range records[0] {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		col[strings.ReplaceAll(h, "-", "_")] = i
+	}
+	if _, ok := col["ex_date"]; !ok {
+		return nil, fmt.Errorf("corporate actions %s: missing ex_date column", path)
+	}
+	if _, ok := col["type"]; !ok {
+		return nil, fmt.Errorf("corporate actions %s: missing type column", path)
+	}
+	get := func(rec []string, name string) string {
+		if i, ok := col[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	cfg := DefaultCandleLoaderConfig()
+	var actions []service.CorporateAction
+	var errs []error
+	for n, rec := range records[1:] {
+		line := n + 2
+		a, err := parseCorporateAction(get, rec, cfg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+		actions = append(actions, a)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("corporate actions %s: %w", path, err)
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool { return actions[i].ExDate < actions[j].ExDate })
+	return actions, nil
+}
+
+func parseCorporateAction(get func([]string, string) string, rec []string, cfg CandleLoaderConfig) (service.CorporateAction, error) {
+	var a service.CorporateAction
+
+	t, err := parseDate(get(rec, "ex_date"), cfg.DateLayouts, cfg.Location)
+	if err != nil {
+		return a, fmt.Errorf("ex_date %q: %w", get(rec, "ex_date"), err)
+	}
+	a.ExDate = t.Format("2006-01-02")
+
+	a.Type = service.CorporateActionType(strings.ToLower(get(rec, "type")))
+	switch a.Type {
+	case service.ActionSplit, service.ActionConsolidation, service.ActionBonus, service.ActionRights:
+		if a.Ratio, err = parseActionRatio(get(rec, "ratio")); err != nil {
+			return a, fmt.Errorf("ratio %q: %w", get(rec, "ratio"), err)
+		}
+	case service.ActionDividend:
+	default:
+		return a, fmt.Errorf("unknown type %q", get(rec, "type"))
+	}
+
+	if raw := get(rec, "amount"); raw != "" {
+		if a.Amount, err = strconv.ParseFloat(raw, 64); err != nil {
+			return a, fmt.Errorf("amount %q: %w", raw, err)
+		}
+	}
+	if raw := get(rec, "price"); raw != "" {
+		if a.Price, err = strconv.ParseFloat(raw, 64); err != nil {
+			return a, fmt.Errorf("price %q: %w", raw, err)
+		}
+	}
+	if a.Type == service.ActionDividend && a.Amount <= 0 {
+		return a, errors.New("dividend amount must be positive")
+	}
+	if a.Type == service.ActionRights && a.Price <= 0 {
+		return a, errors.New("rights subscription price must be positive")
+	}
+	return a, nil
+}
+
+// parseActionRatio 支持 "1.5" 或 "a:b"（= a/b）
+func parseActionRatio(raw string) (float64, error) {
+	if raw == "" {
+		return 0, errors.New("empty ratio")
+	}
+	var v float64
+	if a, b, ok := strings.Cut(raw, ":"); ok {
+		num, err := strconv.ParseFloat(strings.TrimSpace(a), 64)
+		if err != nil {
+			return 0, err
+		}
+		den, err := strconv.ParseFloat(strings.TrimSpace(b), 64)
+		if err != nil {
+			return 0, err
+		}
+		if den == 0 {
+			return 0, errors.New("zero denominator")
+		}
+		v = num / den
+	} else {
+		var err error
+		if v, err = strconv.ParseFloat(raw, 64); err != nil {
+			return 0, err
+		}
+	}
+	if v <= 0 {
+		return 0, errors.New("ratio must be positive")
+	}
+	return v, nil
+}
+
+// AdjustStockCandles 按股票的公司行动文件复权，默认路径下没有文件时原样返回
+func AdjustStockCandles(stock model.Stock, candles []service.Candle, opt service.AdjustOptions) ([]service.Candle, error) {
+	actions, err := LoadCorporateActions(stock.ActionsPath())
+	if errors.Is(err, os.ErrNotExist) && stock.ActionsFile == "" {
+		return candles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return service.AdjustCandles(candles, actions, opt)
+}
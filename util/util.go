@@ -1,15 +1,11 @@
 package util
 
 import (
-	"encoding/csv"
 	"errors"
 	"fmt"
 	"github.com/manifoldco/promptui"
-	"go.uber.org/zap"
-	"os"
-	"strconv"
+	"time"
 	"wolf_street/model"
-	"wolf_street/pkginit"
 	"wolf_street/service"
 )
 
@@ -38,15 +34,19 @@ func CliMenuSelectStrategy(size int) (model.Strategy, error) {
 	return strategies[index], nil
 }
 
-func CliMenuSelectStock(size int) (model.Stock, error) {
-	stocks, err := model.GetAllStock()
+// CliMenuSelectStock sector 为空时列出全部股票
+func CliMenuSelectStock(size int, sector string) (model.Stock, error) {
+	stocks, err := model.GetStocksBySector(sector)
 	if err != nil {
 		return model.Stock{}, err
 	}
+	if len(stocks) == 0 {
+		return model.Stock{}, fmt.Errorf("no stocks in sector %q", sector)
+	}
 
 	displayList := make([]string, len(stocks))
 	for i, stock := range stocks {
-		displayList[i] = fmt.Sprintf("%s [ %s ]  (%s)", stock.Code, stock.Number, stock.Name)
+		displayList[i] = fmt.Sprintf("%s [ %s ]  (%s)  %s / %s", stock.Code, stock.Number, stock.Name, stock.Board, stock.Sector)
 	}
 
 	prompt := promptui.Select{
@@ -63,57 +63,53 @@ func CliMenuSelectStock(size int) (model.Stock, error) {
 	return stocks[index], nil
 }
 
+// LoadStockCandles loads candle data from the stock's configured data file
+func LoadStockCandles(stock model.Stock) ([]service.Candle, error) {
+	if stock.Code == "" {
+		return nil, errors.New("stock code is empty")
+	}
+	return LoadCandleFile(stock.DataPath())
+}
+
 // LoadCandleData loads CSV file for given stock code and returns candle slice
 func LoadCandleData(stockCode, stockNumber string) ([]service.Candle, error) {
+	return LoadStockCandles(model.Stock{Code: stockCode, Number: stockNumber})
+}
 
-	if stockCode == "" {
-		return nil, errors.New("stock code is empty")
-	}
+// LoadCandleFile loads candle CSV file from filePath with the default loader config.
+// A *CandleParseError is returned together with the valid candles when some rows are bad.
+func LoadCandleFile(filePath string) ([]service.Candle, error) {
+	return LoadCandleFileWithConfig(filePath, DefaultCandleLoaderConfig())
+}
 
-	filePath := "./data_set/" + stockCode + "_" + stockNumber + "_data.csv"
-	file, err := os.Open(filePath)
-	if err != nil {
-		// 判断是否为文件不存在错误
-		if errors.Is(err, os.ErrNotExist) {
-			pkginit.Logger.Warn("Candle data file not found", zap.String("filePath", filePath))
-			return nil, os.ErrNotExist
+// FilterCandlesByDate 保留 [from, to] 区间内的 K 线，from/to 为空表示不限，格式 YYYY-MM-DD
+func FilterCandlesByDate(candles []service.Candle, from, to string) ([]service.Candle, error) {
+	var fromT, toT time.Time
+	var err error
+	if from != "" {
+		if fromT, err = time.ParseInLocation("2006-01-02", from, bursaLocation); err != nil {
+			return nil, fmt.Errorf("invalid from date %q: %w", from, err)
 		}
-
-		// 其他打开文件的错误
-		pkginit.Logger.Error("Failed to open candle data file", zap.String("filePath", filePath), zap.Error(err))
-		return nil, err
 	}
-	defer file.Close()
-
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
-	if err != nil {
-		pkginit.Logger.Error("Failed to read CSV records", zap.Error(err))
-		return nil, err
+	if to != "" {
+		if toT, err = time.ParseInLocation("2006-01-02", to, bursaLocation); err != nil {
+			return nil, fmt.Errorf("invalid to date %q: %w", to, err)
+		}
 	}
 
-	var candles []service.Candle
-	for _, record := range records[1:] { // Skip header row
-		closeDate := record[0]
-		open, _ := strconv.ParseFloat(record[1], 64)
-		high, _ := strconv.ParseFloat(record[2], 64)
-		low, _ := strconv.ParseFloat(record[3], 64)
-		closePrice, err := strconv.ParseFloat(record[4], 64)
-		if err != nil {
-			pkginit.Logger.Warn("Invalid close price", zap.String("date", closeDate), zap.String("value", record[1]))
+	var out []service.Candle
+	for _, c := range candles {
+		t := c.Time
+		if t.IsZero() {
+			cfg := DefaultCandleLoaderConfig()
+			if t, err = parseDate(c.Date, cfg.DateLayouts, cfg.Location); err != nil {
+				return nil, fmt.Errorf("candle date %q: %w", c.Date, err)
+			}
+		}
+		if (!fromT.IsZero() && t.Before(fromT)) || (!toT.IsZero() && t.After(toT)) {
 			continue
 		}
-
-		//pkginit.Logger.Debug("Parsing Candle", zap.String("date", closeDate), zap.Float64("closePrice", closePrice))
-
-		candles = append(candles, service.Candle{
-			Date:  closeDate,
-			Open:  open,
-			High:  high,
-			Low:   low,
-			Close: closePrice,
-		})
+		out = append(out, c)
 	}
-
-	return candles, nil
+	return out, nil
 }